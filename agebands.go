@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+const defaultAgeBands = "7d,30d,90d,1y"
+
+// ageEdge is one boundary between age bands, keeping the user's spelling for labels
+type ageEdge struct {
+	label string
+	age   time.Duration
+}
+
+// ageBand is the aggregate of all files whose age falls within one band
+type ageBand struct {
+	Label string `json:"label"`
+	Size  int64  `json:"size"`
+	Count int    `json:"count"`
+}
+
+// parseAgeBands parses a comma-separated list of band edges, e.g. "7d,30d,1y"
+func parseAgeBands(s string) ([]ageEdge, error) {
+	var edges []ageEdge
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		age, err := scan.ParseAge(part)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, ageEdge{label: part, age: age})
+	}
+	if len(edges) == 0 {
+		return nil, fmt.Errorf("no age bands given")
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		return edges[i].age < edges[j].age
+	})
+	return edges, nil
+}
+
+// computeAgeBands buckets every file by how long ago it was modified.
+// It returns len(edges)+1 bands, youngest first.
+func computeAgeBands(stats *scan.Stats, edges []ageEdge, now time.Time) ([]ageBand, error) {
+	bands := make([]ageBand, len(edges)+1)
+	bands[0].Label = "< " + edges[0].label
+	for i := 1; i < len(edges); i++ {
+		bands[i].Label = edges[i-1].label + " – " + edges[i].label
+	}
+	bands[len(edges)].Label = "> " + edges[len(edges)-1].label
+
+	err := stats.EachFile(func(_ string, f scan.FileDetail) {
+		age := now.Sub(f.ModTime)
+		i := sort.Search(len(edges), func(i int) bool {
+			return age < edges[i].age
+		})
+		bands[i].Size += f.Size
+		bands[i].Count++
+	})
+	return bands, err
+}
+
+// printAgeBands prints the age band block in the same layout as the summary
+func printAgeBands(bands []ageBand) {
+	fmt.Println("==================================")
+	fmt.Println(" Age Bands: Storage by Last Modified ")
+	fmt.Println("==================================")
+	for _, b := range bands {
+		fmt.Printf("%s: %s (%s)\n", b.Label, formatSize(b.Size), formatFileCount(b.Count))
+	}
+	fmt.Println("==================================")
+}
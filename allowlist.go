@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// loadAllowlist reads permitted extensions, one per line. Blank lines and
+// lines starting with # are ignored, and a leading dot is optional. Entries
+// are compared against the same keys the summary shows, so "no extension"
+// allows files without a recognised extension. Entries are lower-cased
+// unless caseSensitive is set.
+func loadAllowlist(path string, caseSensitive bool) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening allowlist: %w", err)
+	}
+	defer f.Close()
+
+	allowed := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ext := strings.TrimPrefix(line, ".")
+		if !caseSensitive {
+			ext = strings.ToLower(ext)
+		}
+		allowed[ext] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading allowlist: %w", err)
+	}
+	return allowed, nil
+}
+
+// allowlistViolation is a file whose extension is not in the allowlist
+type allowlistViolation struct {
+	Ext  string
+	File scan.FileDetail
+}
+
+// findViolations returns every file whose extension is not allowed, largest first
+func findViolations(stats *scan.Stats, allowed map[string]bool) ([]allowlistViolation, error) {
+	var violations []allowlistViolation
+	err := stats.EachFile(func(ext string, f scan.FileDetail) {
+		if !allowed[ext] {
+			violations = append(violations, allowlistViolation{Ext: ext, File: f})
+		}
+	})
+	sort.Slice(violations, func(i, j int) bool {
+		return violations[i].File.Size > violations[j].File.Size
+	})
+	return violations, err
+}
+
+// printViolations prints the disallowed files followed by a one-line summary
+func printViolations(violations []allowlistViolation, redactRoot string) {
+	fmt.Println("==================================")
+	fmt.Println(" Allowlist Violations ")
+	fmt.Println("==================================")
+
+	var totalSize int64
+	exts := make(map[string]bool)
+	for i, v := range violations {
+		prefix := "├──"
+		if i == len(violations)-1 {
+			prefix = "└──"
+		}
+		fmt.Printf("%s %s (%s)\n", prefix, displayPath(v.File.Path, redactRoot), formatSize(v.File.Size))
+		totalSize += v.File.Size
+		exts[extLabel(v.Ext)] = true
+	}
+
+	names := make([]string, 0, len(exts))
+	for ext := range exts {
+		names = append(names, ext)
+	}
+	sort.Strings(names)
+	fmt.Println("==================================")
+	fmt.Printf("%s file(s) (%s) with disallowed extensions: %s\n", formatCount(int64(len(violations))), formatSize(totalSize), strings.Join(names, ", "))
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// fileHeap is a min-heap by size, used to keep the n largest files seen so far
+type fileHeap []scan.FileDetail
+
+func (h fileHeap) Len() int { return len(h) }
+func (h fileHeap) Less(i, j int) bool {
+	if h[i].Size != h[j].Size {
+		return h[i].Size < h[j].Size
+	}
+	return h[i].Path > h[j].Path
+}
+func (h fileHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *fileHeap) Push(x any)   { *h = append(*h, x.(scan.FileDetail)) }
+func (h *fileHeap) Pop() any {
+	old := *h
+	f := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return f
+}
+
+// biggestFiles returns the n largest files across all extensions, largest first.
+// Only n files are held at a time, so it also works on spilled stats.
+func biggestFiles(stats *scan.Stats, n int) ([]scan.FileDetail, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	h := &fileHeap{}
+	err := stats.EachFile(func(_ string, f scan.FileDetail) {
+		if h.Len() < n {
+			heap.Push(h, f)
+		} else if (*h)[0].Size < f.Size {
+			(*h)[0] = f
+			heap.Fix(h, 0)
+		}
+	})
+
+	files := []scan.FileDetail(*h)
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Size != files[j].Size {
+			return files[i].Size > files[j].Size
+		}
+		return files[i].Path < files[j].Path
+	})
+	return files, err
+}
+
+// printBiggest prints the global largest-files list
+func printBiggest(files []scan.FileDetail, redactRoot string) {
+	fmt.Println("==================================")
+	fmt.Printf(" Biggest Files (%d) \n", len(files))
+	fmt.Println("==================================")
+	for i, f := range files {
+		prefix := "├──"
+		if i == len(files)-1 {
+			prefix = "└──"
+		}
+		fmt.Printf("%s %s (%s)\n", prefix, displayPath(f.Path, redactRoot), formatSize(f.Size))
+	}
+	fmt.Println("==================================")
+}
+
+// hotFolders merges the folder sizes of every extension and returns the n
+// folders holding the most bytes of counted files, largest first. Sizes are
+// of the files directly in each folder, as in the -d view.
+func hotFolders(stats *scan.Stats, n int) []scan.FileDetail {
+	merged := make(map[string]int64)
+	for _, folders := range stats.Folders {
+		for dir, size := range folders {
+			merged[dir] += size
+		}
+	}
+	list := sortedFolders(merged, scan.FileLess("size", false))
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// printHotFolders prints the folders from hotFolders
+func printHotFolders(folders []scan.FileDetail, redactRoot string) {
+	fmt.Println("==================================")
+	fmt.Printf(" Biggest Folders (%d) \n", len(folders))
+	fmt.Println("==================================")
+	for i, f := range folders {
+		prefix := "├──"
+		if i == len(folders)-1 {
+			prefix = "└──"
+		}
+		fmt.Printf("%s %s (%s)\n", prefix, displayPath(f.Path, redactRoot), formatSize(f.Size))
+	}
+	fmt.Println("==================================")
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// cacheVersion is bumped whenever the layout or meaning of scanCache changes, so old
+// cache files are ignored instead of misread
+const cacheVersion = 2
+
+// scanCache is a finished scan saved by --cache, with the modification times
+// it is checked against before being reused
+type scanCache struct {
+	Key   string
+	Dirs  map[string]time.Time // the roots and every folder below them
+	Stats *scan.Stats
+}
+
+// cacheKey identifies a scan by its roots and every option that changes what
+// is recorded. Output options are left out, so they can change between runs.
+func cacheKey(roots []string, opts scan.Options, byMIME, byOwner bool, categories, merges map[string]string) (string, error) {
+	abs := make([]string, len(roots))
+	for i, root := range roots {
+		a, err := filepath.Abs(root)
+		if err != nil {
+			return "", err
+		}
+		abs[i] = a
+	}
+	data, err := json.Marshal(struct {
+		Version        int
+		Roots, Abs     []string
+		Filter         scan.Filter
+		ExtensionRules scan.ExtensionRules
+		Fd             bool
+		HardlinksOnce  bool
+		DiskUsage      bool
+		MIME, Owner    bool
+		Categories     map[string]string
+		Merges         map[string]string
+	}{cacheVersion, roots, abs, opts.Filter, opts.ExtensionRules, opts.FdCommand != "", opts.HardlinksOnce, opts.DiskUsage, byMIME, byOwner, categories, merges})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cacheFile is where the scan with key is cached
+func cacheFile(key string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding cache directory: %w", err)
+	}
+	return filepath.Join(dir, "extdust", key+".gob"), nil
+}
+
+// loadCache returns the scan cached under key, or nil when there is none or
+// one of its roots or folders has been modified or removed since it was saved
+func loadCache(key string) *scan.Stats {
+	target, err := cacheFile(key)
+	if err != nil {
+		return nil
+	}
+	f, err := os.Open(target)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var c scanCache
+	if err := gob.NewDecoder(f).Decode(&c); err != nil || c.Key != key || c.Stats == nil {
+		return nil
+	}
+	for dir, mtime := range c.Dirs {
+		info, err := os.Stat(dir)
+		if err != nil || !info.ModTime().Equal(mtime) {
+			return nil
+		}
+	}
+	return c.Stats
+}
+
+// saveCache writes stats to the cache under key, along with the current
+// modification times of roots and of every folder below them
+func saveCache(key string, roots []string, stats *scan.Stats) error {
+	target, err := cacheFile(key)
+	if err != nil {
+		return err
+	}
+
+	c := scanCache{Key: key, Dirs: make(map[string]time.Time), Stats: stats}
+	record := func(dir string) error {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return err
+		}
+		c.Dirs[dir] = info.ModTime()
+		return nil
+	}
+	for _, root := range roots {
+		if err := record(root); err != nil {
+			return fmt.Errorf("error writing cache: %w", err)
+		}
+		// every folder counts, not only those with counted files: a file
+		// added anywhere changes the mtime of its folder, and a new folder
+		// that of its parent. An archive root is a file, so its own mtime
+		// covers its entries.
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// unreadable now, and so skipped by the scan as well
+				if d != nil && d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				if err := record(path); err != nil {
+					return fs.SkipDir
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("error writing cache: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("error writing cache: %w", err)
+	}
+	// write a temporary file first, so a concurrent run never reads half a cache
+	tmp, err := os.CreateTemp(filepath.Dir(target), "scan-*.tmp")
+	if err != nil {
+		return fmt.Errorf("error writing cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := gob.NewEncoder(tmp).Encode(&c); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("error writing cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+func TestCacheInvalidation(t *testing.T) {
+	// os.UserCacheDir reads XDG_CACHE_HOME on Linux and HOME elsewhere
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	write := func(path string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		change func(root string)
+	}{
+		{"file in a folder without counted files", func(root string) { write(filepath.Join(root, "docs", "new.go")) }},
+		{"file in a new nested folder", func(root string) { write(filepath.Join(root, "src", "deep", "x", "new.go")) }},
+		{"removed folder", func(root string) {
+			if err := os.RemoveAll(filepath.Join(root, "docs", "old")); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			write(filepath.Join(root, "src", "main.go"))
+			write(filepath.Join(root, "docs", "readme.md"))
+			write(filepath.Join(root, "docs", "old", "notes.md"))
+
+			opts := scan.Options{Filter: scan.Filter{Extensions: "go"}}
+			key, err := cacheKey([]string{root}, opts, false, false, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stats, err := scan.Scan(root, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := saveCache(key, []string{root}, stats); err != nil {
+				t.Fatal(err)
+			}
+			if loadCache(key) == nil {
+				t.Fatal("unchanged tree: cache not reused")
+			}
+
+			tt.change(root)
+			if cached := loadCache(key); cached != nil {
+				t.Errorf("cache reused after the change: %v", cached.Sizes)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// otherCategory is the category for extensions not listed in any category
+const otherCategory = "Other"
+
+// defaultCategories is the built-in --categories mapping
+var defaultCategories = map[string][]string{
+	"Images":    {"jpg", "jpeg", "png", "gif", "webp", "bmp", "tif", "tiff", "svg", "heic", "ico", "raw"},
+	"Video":     {"mp4", "mkv", "mov", "avi", "webm", "wmv", "flv", "m4v", "mpg", "mpeg"},
+	"Audio":     {"mp3", "flac", "wav", "aac", "ogg", "m4a", "opus", "wma"},
+	"Documents": {"pdf", "doc", "docx", "xls", "xlsx", "ppt", "pptx", "odt", "ods", "odp", "txt", "md", "rtf", "csv", "epub"},
+	"Code":      {"go", "c", "h", "cpp", "hpp", "cc", "rs", "py", "js", "ts", "tsx", "jsx", "java", "kt", "rb", "php", "cs", "sh", "html", "css", "json", "yaml", "yml", "toml", "xml", "sql"},
+	"Archives":  {"zip", "tar", "gz", "tgz", "bz2", "xz", "zst", "7z", "rar", "tar.gz", "tar.bz2", "tar.xz", "tar.zst"},
+}
+
+// loadCategories returns an extension -> category map. A JSON file of the
+// form {"Images": ["jpg", "png"], ...} overrides the default list of each
+// category it names and can add new categories; extensions it lists move to
+// the category given in the file.
+func loadCategories(path string) (map[string]string, error) {
+	categories := make(map[string][]string, len(defaultCategories))
+	for name, exts := range defaultCategories {
+		categories[name] = exts
+	}
+
+	var override map[string][]string
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading categories file: %w", err)
+		}
+		if err := json.Unmarshal(data, &override); err != nil {
+			return nil, fmt.Errorf("error parsing categories file %s: %w", path, err)
+		}
+		for name, exts := range override {
+			categories[name] = exts
+		}
+	}
+
+	byExt := make(map[string]string)
+	for name, exts := range categories {
+		if _, ok := override[name]; ok {
+			continue // assigned last so the file wins over the defaults
+		}
+		for _, ext := range exts {
+			byExt[normalizeCategoryExt(ext)] = name
+		}
+	}
+	for name, exts := range override {
+		for _, ext := range exts {
+			byExt[normalizeCategoryExt(ext)] = name
+		}
+	}
+	return byExt, nil
+}
+
+func normalizeCategoryExt(ext string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+}
+
+// categoryOf maps a stats key (an extension) to its category
+func categoryOf(byExt map[string]string) func(string) string {
+	return func(ext string) string {
+		if name, ok := byExt[strings.ToLower(ext)]; ok {
+			return name
+		}
+		return otherCategory
+	}
+}
+
+// categoryGroup is one category of --summary-as-tree and its extensions
+type categoryGroup struct {
+	Name  string
+	Size  int64
+	Count int
+	Exts  []string // in summary order
+}
+
+// groupByCategory sorts the extensions of the summary under their
+// categories. Categories are ordered by subtotal, largest first (smallest
+// first with reverse), then by name; each keeps its extensions in the order
+// of sortedExtensions.
+func groupByCategory(sortedExtensions []string, sizes map[string]int64, counts map[string]int, byExt map[string]string, reverse bool) []categoryGroup {
+	category := categoryOf(byExt)
+	index := make(map[string]int)
+	var groups []categoryGroup
+	for _, ext := range sortedExtensions {
+		name := category(ext)
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, categoryGroup{Name: name})
+		}
+		groups[i].Size += sizes[ext]
+		groups[i].Count += counts[ext]
+		groups[i].Exts = append(groups[i].Exts, ext)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Size != groups[j].Size {
+			if reverse {
+				return groups[i].Size < groups[j].Size
+			}
+			return groups[i].Size > groups[j].Size
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// printCategoryTree prints the summary as category headers with their
+// subtotal and their extensions indented beneath, drawn with ASCII with ascii
+func printCategoryTree(groups []categoryGroup, sizes map[string]int64, counts map[string]int, totalSize int64, total, showCount, showPercent, ascii bool) {
+	branch, last := "├──", "└──"
+	if ascii {
+		branch, last = "|--", "`--"
+	}
+	extras := func(size int64, count int) string {
+		var parts []string
+		if showCount {
+			parts = append(parts, formatFileCount(count))
+		}
+		if showPercent {
+			percent := 0.0
+			if totalSize > 0 {
+				percent = float64(size) * 100 / float64(totalSize)
+			}
+			parts = append(parts, fmt.Sprintf("%.1f%%", percent))
+		}
+		if len(parts) == 0 {
+			return ""
+		}
+		return " (" + strings.Join(parts, ", ") + ")"
+	}
+
+	fmt.Println("==================================")
+	fmt.Println(" Summary: Storage per Category ")
+	fmt.Println("==================================")
+	for _, g := range groups {
+		fmt.Printf("%s %s%s\n", bold(g.Name+":"), colorSize(g.Size), extras(g.Size, g.Count))
+		for i, ext := range g.Exts {
+			prefix := branch
+			if i == len(g.Exts)-1 {
+				prefix = last
+			}
+			fmt.Printf("%s %s %s%s\n", dim(prefix), extLabel(ext)+":", colorSize(sizes[ext]), extras(sizes[ext], counts[ext]))
+		}
+	}
+	fmt.Println("==================================")
+
+	if total {
+		fmt.Printf("%s %s\n", bold("Total :"), colorSize(totalSize))
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGroupByCategory(t *testing.T) {
+	byExt := map[string]string{"jpg": "Images", "png": "Images", "go": "Code", "md": "Documents"}
+	sizes := map[string]int64{"jpg": 50, "go": 40, "png": 30, "zip": 20, "md": 5, "no extension": 1}
+	counts := map[string]int{"jpg": 5, "go": 4, "png": 3, "zip": 2, "md": 1, "no extension": 1}
+	sorted := []string{"jpg", "go", "png", "zip", "md", "no extension"}
+
+	want := []categoryGroup{
+		{Name: "Images", Size: 80, Count: 8, Exts: []string{"jpg", "png"}},
+		{Name: "Code", Size: 40, Count: 4, Exts: []string{"go"}},
+		{Name: "Other", Size: 21, Count: 3, Exts: []string{"zip", "no extension"}},
+		{Name: "Documents", Size: 5, Count: 1, Exts: []string{"md"}},
+	}
+	if got := groupByCategory(sorted, sizes, counts, byExt, false); !reflect.DeepEqual(got, want) {
+		t.Errorf("groupByCategory = %+v, want %+v", got, want)
+	}
+
+	var names []string
+	for _, g := range groupByCategory(sorted, sizes, counts, byExt, true) {
+		names = append(names, g.Name)
+	}
+	if want := []string{"Documents", "Other", "Code", "Images"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("reversed order = %v, want %v", names, want)
+	}
+}
+
+func TestPrintCategoryTree(t *testing.T) {
+	groups := []categoryGroup{
+		{Name: "Images", Size: 80, Count: 8, Exts: []string{"jpg", "png"}},
+		{Name: "Code", Size: 40, Count: 4, Exts: []string{"go"}},
+	}
+	sizes := map[string]int64{"jpg": 50, "png": 30, "go": 40}
+	counts := map[string]int{"jpg": 5, "png": 3, "go": 4}
+
+	out := captureStdout(t, func() {
+		printCategoryTree(groups, sizes, counts, 120, false, true, false, false)
+	})
+	for _, line := range []string{
+		"Images: 80 bytes (8 files)\n├── JPG: 50 bytes (5 files)\n└── PNG: 30 bytes (3 files)\n",
+		"Code: 40 bytes (4 files)\n└── GO: 40 bytes (4 files)\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("output is missing\n%s\nin\n%s", line, out)
+		}
+	}
+
+	out = captureStdout(t, func() {
+		printCategoryTree(groups, sizes, counts, 120, false, false, false, true)
+	})
+	if !strings.Contains(out, "|-- JPG: 50 bytes\n`-- PNG: 30 bytes\n") {
+		t.Errorf("--ascii output is not drawn with ASCII branches:\n%s", out)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"math"
+	"strings"
+)
+
+// defaultChartWidth is the line width assumed for --chart when stdout is not a terminal
+const defaultChartWidth = 80
+
+// minBarCells is the shortest a full-length bar is made, however narrow the output
+const minBarCells = 10
+
+// eighths are the partial block characters for 1/8 to 7/8 of a cell
+var eighths = []rune("▏▎▍▌▋▊▉")
+
+// renderBar draws a bar of value/scale of cells columns. Block characters give
+// 1/8-cell resolution; ascii falls back to whole cells of '#'. A non-zero
+// value always gets at least the thinnest mark so it does not read as empty.
+func renderBar(value, scale int64, cells int, ascii bool) string {
+	if scale <= 0 || value <= 0 || cells <= 0 {
+		return ""
+	}
+	ratio := math.Min(float64(value)/float64(scale), 1)
+
+	if ascii {
+		n := int(math.Round(ratio * float64(cells)))
+		return strings.Repeat("#", max(n, 1))
+	}
+
+	units := int(math.Round(ratio * float64(cells*8)))
+	units = max(units, 1)
+	bar := strings.Repeat("█", units/8)
+	if rest := units % 8; rest > 0 {
+		bar += string(eighths[rest-1])
+	}
+	return bar
+}
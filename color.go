@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// useColor enables ANSI styling in the text report (set from --color).
+// The helpers below return their input unchanged when it is off, and JSON
+// and CSV output never goes through them.
+var useColor bool
+
+const (
+	ansiReset  = "\033[0m"
+	ansiBold   = "\033[1m"
+	ansiDim    = "\033[2m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+)
+
+// resolveColor decides whether to color output for --color=auto|always|never.
+// auto colors only a terminal stdout, and only when NO_COLOR is unset.
+func resolveColor(mode string) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		return os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout), nil
+	default:
+		return false, fmt.Errorf("invalid --color %q: must be auto, always or never", mode)
+	}
+}
+
+func style(code, s string) string {
+	if !useColor {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// bold is used for extension names
+func bold(s string) string { return style(ansiBold, s) }
+
+// dim is used for tree connectors
+func dim(s string) string { return style(ansiDim, s) }
+
+// colorSize renders size with formatSize, graded by magnitude:
+// green below 1 MiB, yellow below 1 GiB, red from 1 GiB
+func colorSize(size int64) string {
+	s := formatSize(size)
+	switch {
+	case size >= 1<<30:
+		return style(ansiRed, s)
+	case size >= 1<<20:
+		return style(ansiYellow, s)
+	default:
+		return style(ansiGreen, s)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix is prepended to upper-cased flag names to form environment variables
+const envPrefix = "EXTDUST_"
+
+// envName returns the environment variable for a flag, e.g. EXTDUST_SORT_COUNT for --sort-count
+func envName(flag string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// applyEnv sets every flag not given on the command line from its EXTDUST_*
+// variable, if that is set. Variables hold a single value, even for
+// repeatable flags.
+func applyEnv(flags *pflag.FlagSet) error {
+	var err error
+	flags.VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed {
+			return
+		}
+		if value, ok := os.LookupEnv(envName(f.Name)); ok {
+			if setErr := flags.Set(f.Name, value); setErr != nil {
+				err = fmt.Errorf("invalid %s: %w", envName(f.Name), setErr)
+			}
+		}
+	})
+	return err
+}
+
+// configFileName is looked for in the current directory before the user config directory
+const configFileName = ".extdust.yaml"
+
+// findConfigFile returns the config file to load: explicit when set (it must
+// exist), otherwise ./.extdust.yaml or <user config dir>/extdust/config.yaml,
+// whichever exists first. It returns "" when there is none.
+func findConfigFile(explicit string) (string, error) {
+	if explicit != "" {
+		if _, err := os.Stat(explicit); err != nil {
+			return "", fmt.Errorf("error reading config file: %w", err)
+		}
+		return explicit, nil
+	}
+
+	candidates := []string{configFileName}
+	if dir, err := os.UserConfigDir(); err == nil {
+		candidates = append(candidates, filepath.Join(dir, "extdust", "config.yaml"))
+	}
+	for _, c := range candidates {
+		if _, err := os.Stat(c); err == nil {
+			return c, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("error reading config file: %w", err)
+		}
+	}
+	return "", nil
+}
+
+// applyConfig reads a YAML file whose keys are long flag names, e.g.
+//
+//	ext: go,md
+//	limit: 20
+//	files: true
+//	exclude: [node_modules, "*.log"]
+//
+// and sets every flag that was not given on the command line. Lists set
+// repeatable flags once per item.
+func applyConfig(flags *pflag.FlagSet, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+
+	// sorted so errors are reported in a stable order
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		flag := flags.Lookup(key)
+		if flag == nil || key == "config" {
+			return fmt.Errorf("%s: unknown option %q", path, key)
+		}
+		if flag.Changed {
+			continue
+		}
+		items, ok := values[key].([]any)
+		if !ok {
+			items = []any{values[key]}
+		}
+		for _, item := range items {
+			if err := flags.Set(key, fmt.Sprint(item)); err != nil {
+				return fmt.Errorf("%s: invalid %s: %w", path, key, err)
+			}
+		}
+	}
+	return nil
+}
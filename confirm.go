@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// isTerminal reports whether f is attached to a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// looksLarge cheaply estimates whether scanning root will take a long time.
+// The filesystem root and the home directory always qualify; anything else
+// qualifies when its top-level entry count reaches threshold.
+func looksLarge(root string, threshold int) (bool, string) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return false, ""
+	}
+	abs = filepath.Clean(abs)
+
+	if abs == filepath.VolumeName(abs)+string(filepath.Separator) {
+		return true, "it is the filesystem root"
+	}
+	if home, err := os.UserHomeDir(); err == nil && abs == filepath.Clean(home) {
+		return true, "it is your home directory"
+	}
+
+	if threshold <= 0 {
+		return false, ""
+	}
+	dir, err := os.Open(abs)
+	if err != nil {
+		return false, ""
+	}
+	defer dir.Close()
+
+	// only read as many names as needed to cross the threshold
+	names, _ := dir.Readdirnames(threshold)
+	if len(names) >= threshold {
+		return true, fmt.Sprintf("it has at least %d top-level entries", threshold)
+	}
+	return false, ""
+}
+
+// confirmLargeScan asks before scanning a root that looks large. It only
+// prompts when stdin is a terminal; non-interactive runs always proceed.
+func confirmLargeScan(root string, threshold int) bool {
+	if !isTerminal(os.Stdin) {
+		return true
+	}
+	large, reason := looksLarge(root, threshold)
+	if !large {
+		return true
+	}
+
+	// on stderr, so the prompt is seen even when stdout goes to a file
+	fmt.Fprintf(os.Stderr, "%s may take a long time to scan (%s). Continue? [y/N] ", root, reason)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
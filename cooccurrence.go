@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// extPair is an unordered pair of extensions and the number of directories holding both
+type extPair struct {
+	A     string `json:"a"`
+	B     string `json:"b"`
+	Count int    `json:"directories"`
+}
+
+// computeCooccurrence inverts stats.Folders into directory -> extensions and
+// counts, for every pair of extensions, how many directories contain both.
+// Pairs are ranked by directory count, then by name for stable output.
+func computeCooccurrence(stats *scan.Stats) []extPair {
+	dirExts := make(map[string][]string)
+	for ext, folders := range stats.Folders {
+		for dir := range folders {
+			dirExts[dir] = append(dirExts[dir], ext)
+		}
+	}
+
+	counts := make(map[[2]string]int)
+	for _, exts := range dirExts {
+		sort.Strings(exts)
+		for i := 0; i < len(exts); i++ {
+			for j := i + 1; j < len(exts); j++ {
+				counts[[2]string{exts[i], exts[j]}]++
+			}
+		}
+	}
+
+	pairs := make([]extPair, 0, len(counts))
+	for k, n := range counts {
+		pairs = append(pairs, extPair{A: k[0], B: k[1], Count: n})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Count != pairs[j].Count {
+			return pairs[i].Count > pairs[j].Count
+		}
+		if pairs[i].A != pairs[j].A {
+			return pairs[i].A < pairs[j].A
+		}
+		return pairs[i].B < pairs[j].B
+	})
+	return pairs
+}
+
+// printCooccurrence prints the top extension pairs in the same layout as the summary
+func printCooccurrence(pairs []extPair, limit int) {
+	fmt.Println("==================================")
+	fmt.Println(" Extensions Found Together ")
+	fmt.Println("==================================")
+	if len(pairs) == 0 {
+		fmt.Println("No directory contains more than one extension.")
+	}
+	for i, p := range pairs {
+		if i == limit {
+			break
+		}
+		dirs := "directories"
+		if p.Count == 1 {
+			dirs = "directory"
+		}
+		fmt.Printf("%s + %s: %d %s\n", extLabel(p.A), extLabel(p.B), p.Count, dirs)
+	}
+	fmt.Println("==================================")
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// writeCSV writes the per-extension summary as RFC 4180 CSV, in summary order.
+// A target of "-" means stdout. With total, a final "total" row is appended
+// with totalSize (see summaryTotal) and the files of the listed extensions,
+// as in the Markdown table.
+func writeCSV(target string, sortedExtensions []string, stats *scan.Stats, totalSize int64, total bool) error {
+	var out io.Writer = os.Stdout
+	if target != "-" {
+		f, err := os.Create(target)
+		if err != nil {
+			return fmt.Errorf("error creating CSV file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	w := csv.NewWriter(out)
+	w.Write([]string{"extension", "total_bytes", "file_count", "human_size"})
+
+	var totalCount int
+	for _, ext := range sortedExtensions {
+		size, count := stats.Sizes[ext], stats.Counts[ext]
+		w.Write([]string{ext, strconv.FormatInt(size, 10), strconv.Itoa(count), formatSize(size)})
+		totalCount += count
+	}
+	if total {
+		w.Write([]string{"total", strconv.FormatInt(totalSize, 10), strconv.Itoa(totalCount), formatSize(totalSize)})
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("error writing CSV: %w", err)
+	}
+	return nil
+}
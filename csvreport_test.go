@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+func TestWriteCSVTotal(t *testing.T) {
+	// --min-size 5 --top 2 --total=all --csv, prepared as Run does
+	prepare := func() (*scan.Stats, []string) {
+		stats := scan.NewStats()
+		stats.Sizes = map[string]int64{"jpg": 100, "go": 50, "md": 10, "txt": 8, "log": 1}
+		stats.Counts = map[string]int{"jpg": 2, "go": 5, "md": 1, "txt": 4, "log": 3}
+		exts := scan.SortedExtensions(stats.Sizes, stats.Counts, false, false, false)
+		exts = filterMinSize(exts, stats.Sizes, 5)
+		return stats, collapseToTop(stats, exts, 2, "other")
+	}
+
+	tests := []struct {
+		unfiltered bool
+		want       string
+	}{
+		{false, "total,168,12,168 bytes\n"},
+		{true, "total,169,12,169 bytes\n"},
+	}
+	for _, tt := range tests {
+		stats, exts := prepare()
+		totalSize := summaryTotal(stats, exts, tt.unfiltered)
+		target := filepath.Join(t.TempDir(), "out.csv")
+		if err := writeCSV(target, exts, stats, totalSize, true); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := string(data)
+		if !strings.HasPrefix(got, "extension,total_bytes,file_count,human_size\njpg,100,2,100 bytes\ngo,50,5,50 bytes\nother,18,5,18 bytes\n") {
+			t.Errorf("unfiltered=%v: unexpected rows:\n%s", tt.unfiltered, got)
+		}
+		if !strings.HasSuffix(got, tt.want) {
+			t.Errorf("unfiltered=%v: total row in\n%s\nwant %q", tt.unfiltered, got, tt.want)
+		}
+	}
+}
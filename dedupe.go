@@ -0,0 +1,167 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// dupGroup is a set of files with identical content
+type dupGroup struct {
+	Hash        string   `json:"sha256"`
+	Size        int64    `json:"size"`
+	Paths       []string `json:"paths"`
+	Reclaimable int64    `json:"reclaimable"` // size of every copy but one
+}
+
+// dupReport is the result of --dedupe
+type dupReport struct {
+	Groups      []dupGroup       `json:"groups"`
+	Reclaimable int64            `json:"reclaimable"`
+	ByExtension map[string]int64 `json:"reclaimable_by_extension"`
+}
+
+// findDuplicates hashes, with jobs workers, only the files that share their
+// size with another file, and groups those with equal SHA-256 sums. Empty
+// files are ignored. In each group the first path (in sort order) counts as
+// the original, so the others' extensions are charged the reclaimable bytes.
+// Files that cannot be read are recorded as scan errors.
+func findDuplicates(stats *scan.Stats, jobs int) (dupReport, error) {
+	type candidate struct {
+		ext  string
+		path string
+		size int64
+	}
+	bySize := make(map[int64][]candidate)
+	err := stats.EachFile(func(ext string, f scan.FileDetail) {
+		if f.Size > 0 {
+			bySize[f.Size] = append(bySize[f.Size], candidate{ext: ext, path: f.Path, size: f.Size})
+		}
+	})
+	if err != nil {
+		return dupReport{}, err
+	}
+
+	var todo []candidate
+	for _, files := range bySize {
+		if len(files) > 1 {
+			todo = append(todo, files...)
+		}
+	}
+
+	type hashed struct {
+		candidate
+		sum string
+		err error
+	}
+	if jobs < 1 {
+		jobs = 1
+	}
+	work := make(chan candidate)
+	results := make(chan hashed)
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range work {
+				sum, _, err := hashFile(c.path, sha256.New)
+				results <- hashed{candidate: c, sum: sum, err: err}
+			}
+		}()
+	}
+	go func() {
+		for _, c := range todo {
+			work <- c
+		}
+		close(work)
+		wg.Wait()
+		close(results)
+	}()
+
+	// the size is part of the key so equal sums of different sizes cannot collide
+	type key struct {
+		size int64
+		sum  string
+	}
+	groups := make(map[key][]candidate)
+	for r := range results {
+		if r.err != nil {
+			stats.AddError(r.path, r.err)
+			continue
+		}
+		k := key{r.size, r.sum}
+		groups[k] = append(groups[k], r.candidate)
+	}
+
+	report := dupReport{Groups: []dupGroup{}, ByExtension: make(map[string]int64)}
+	for k, files := range groups {
+		if len(files) < 2 {
+			continue
+		}
+		sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+		g := dupGroup{Hash: k.sum, Size: k.size, Reclaimable: k.size * int64(len(files)-1)}
+		for i, f := range files {
+			g.Paths = append(g.Paths, f.path)
+			if i > 0 {
+				report.ByExtension[f.ext] += f.size
+			}
+		}
+		report.Groups = append(report.Groups, g)
+		report.Reclaimable += g.Reclaimable
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if a.Reclaimable != b.Reclaimable {
+			return a.Reclaimable > b.Reclaimable
+		}
+		return a.Paths[0] < b.Paths[0]
+	})
+	return report, nil
+}
+
+// printDuplicates prints reclaimable space per extension and the top limit groups
+func printDuplicates(report dupReport, limit int, redactRoot string) {
+	fmt.Println("==================================")
+	fmt.Println(" Duplicate Files ")
+	fmt.Println("==================================")
+	if len(report.Groups) == 0 {
+		fmt.Println("No duplicate files found.")
+		fmt.Println("==================================")
+		return
+	}
+
+	exts := make([]string, 0, len(report.ByExtension))
+	for ext := range report.ByExtension {
+		exts = append(exts, ext)
+	}
+	sort.Slice(exts, func(i, j int) bool {
+		if report.ByExtension[exts[i]] != report.ByExtension[exts[j]] {
+			return report.ByExtension[exts[i]] > report.ByExtension[exts[j]]
+		}
+		return exts[i] < exts[j]
+	})
+	for _, ext := range exts {
+		fmt.Printf("%s: %s reclaimable\n", bold(extLabel(ext)), colorSize(report.ByExtension[ext]))
+	}
+	fmt.Println()
+
+	for i, g := range report.Groups {
+		if i == limit {
+			break
+		}
+		fmt.Printf("%d copies of %s (%s reclaimable):\n", len(g.Paths), formatSize(g.Size), formatSize(g.Reclaimable))
+		for j, p := range g.Paths {
+			prefix := "├──"
+			if j == len(g.Paths)-1 {
+				prefix = "└──"
+			}
+			fmt.Printf("%s %s\n", dim(prefix), displayPath(p, redactRoot))
+		}
+	}
+	fmt.Println("==================================")
+	fmt.Printf("%s reclaimable in %d duplicate group(s)\n", formatSize(report.Reclaimable), len(report.Groups))
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// savedExtension is the part of a --json extension entry that --diff compares
+type savedExtension struct {
+	Size      int64 `json:"size"`
+	FileCount int   `json:"file_count"`
+}
+
+// extDelta is the change of one extension between a saved scan and this one
+type extDelta struct {
+	Extension  string `json:"extension"`
+	Status     string `json:"status"` // "new", "removed" or "changed"
+	Size       int64  `json:"size"`
+	SizeDelta  int64  `json:"size_delta"`
+	CountDelta int    `json:"file_count_delta"`
+}
+
+// loadSavedScan reads the extensions of a report previously written with --json
+func loadSavedScan(path string) (map[string]savedExtension, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading saved scan: %w", err)
+	}
+	var doc struct {
+		Extensions []struct {
+			Extension string `json:"extension"`
+			savedExtension
+		} `json:"extensions"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing saved scan %s: %w", path, err)
+	}
+	saved := make(map[string]savedExtension, len(doc.Extensions))
+	for _, e := range doc.Extensions {
+		saved[e.Extension] = e.savedExtension
+	}
+	return saved, nil
+}
+
+// diffScans compares the shown extensions with a saved scan. Unchanged
+// extensions are left out; the rest are ordered by the size of the change,
+// largest first.
+func diffScans(saved map[string]savedExtension, sortedExtensions []string, sizes map[string]int64, counts map[string]int) []extDelta {
+	var deltas []extDelta
+	seen := make(map[string]bool, len(sortedExtensions))
+	for _, ext := range sortedExtensions {
+		seen[ext] = true
+		old, existed := saved[ext]
+		d := extDelta{
+			Extension:  ext,
+			Status:     "changed",
+			Size:       sizes[ext],
+			SizeDelta:  sizes[ext] - old.Size,
+			CountDelta: counts[ext] - old.FileCount,
+		}
+		if !existed {
+			d.Status = "new"
+		} else if d.SizeDelta == 0 && d.CountDelta == 0 {
+			continue
+		}
+		deltas = append(deltas, d)
+	}
+	for ext, old := range saved {
+		if !seen[ext] {
+			deltas = append(deltas, extDelta{Extension: ext, Status: "removed", SizeDelta: -old.Size, CountDelta: -old.FileCount})
+		}
+	}
+
+	abs := func(n int64) int64 {
+		if n < 0 {
+			return -n
+		}
+		return n
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		a, b := abs(deltas[i].SizeDelta), abs(deltas[j].SizeDelta)
+		if a != b {
+			return a > b
+		}
+		return deltas[i].Extension < deltas[j].Extension
+	})
+	return deltas
+}
+
+// formatDelta renders a signed size change, red for growth and green for shrinkage
+func formatDelta(n int64) string {
+	switch {
+	case n > 0:
+		return style(ansiRed, "+"+formatSize(n))
+	case n < 0:
+		return style(ansiGreen, "-"+formatSize(-n))
+	default:
+		return "±0"
+	}
+}
+
+// printDiff prints the per-extension changes since the saved scan at path
+func printDiff(deltas []extDelta, path string) {
+	fmt.Println("==================================")
+	fmt.Printf(" Changes since %s \n", path)
+	fmt.Println("==================================")
+	if len(deltas) == 0 {
+		fmt.Println("No changes.")
+		fmt.Println("==================================")
+		return
+	}
+
+	var total int64
+	for _, d := range deltas {
+		files := formatCount(int64(d.CountDelta)) + " files"
+		if d.CountDelta == 1 || d.CountDelta == -1 {
+			files = formatCount(int64(d.CountDelta)) + " file"
+		}
+		if d.CountDelta >= 0 {
+			files = "+" + files
+		}
+		switch d.Status {
+		case "new":
+			fmt.Printf("%s: new, %s (%s)\n", bold(extLabel(d.Extension)), formatDelta(d.SizeDelta), files)
+		case "removed":
+			fmt.Printf("%s: removed, %s (%s)\n", bold(extLabel(d.Extension)), formatDelta(d.SizeDelta), files)
+		default:
+			fmt.Printf("%s: %s (%s)\n", bold(extLabel(d.Extension)), formatDelta(d.SizeDelta), files)
+		}
+		total += d.SizeDelta
+	}
+	fmt.Println("==================================")
+	fmt.Printf("Total change: %s\n", formatDelta(total))
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/awsms/extdust/pkg/scan"
+	"github.com/spf13/cobra"
+)
+
+// fdFlags are the fd options the scan package relies on, by their long names
+var fdFlags = []struct {
+	short, long string
+}{
+	{"--type", "--type"},
+	{"-H", "--hidden"},
+	{"-I", "--no-ignore"},
+	{"--full-path", "--full-path"},
+	{"--base-directory", "--base-directory"},
+	{"--max-depth", "--max-depth"},
+	{"-L", "--follow"},
+	{"-e", "--extension"},
+}
+
+// newDoctorCmd returns the "doctor" subcommand, which reports which scan
+// engine --engine auto will pick and what the current system supports
+func newDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Show the scan engine in use and the features available on this system",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("Platform:       %s/%s\n", runtime.GOOS, runtime.GOARCH)
+
+			fdCmdName, err := scan.FindFd()
+			if err != nil {
+				fmt.Println("Scan engine:    native (fd/fdfind not found in PATH)")
+			} else {
+				fmt.Printf("Scan engine:    fd (%s)\n", fdCmdName)
+				printFdCapabilities(fdCmdName)
+			}
+
+			fmt.Println("Engines:        fd, native, archive roots")
+			fmt.Println("Archive roots:  tar, tar.gz/tgz, tar.bz2/tbz2, zip")
+
+			algorithms := make([]string, 0, len(hashAlgorithms))
+			for name := range hashAlgorithms {
+				algorithms = append(algorithms, name)
+			}
+			sort.Strings(algorithms)
+			fmt.Printf("Checksums:      %s\n", strings.Join(algorithms, ", "))
+		},
+	}
+}
+
+// printFdCapabilities prints the fd version and whether it knows each flag we pass
+func printFdCapabilities(fdCmdName string) {
+	version, err := exec.Command(fdCmdName, "--version").Output()
+	if err != nil {
+		fmt.Printf("fd version:     unknown (%v)\n", err)
+	} else {
+		fmt.Printf("fd version:     %s\n", strings.TrimSpace(string(version)))
+	}
+
+	help, err := exec.Command(fdCmdName, "--help").Output()
+	if err != nil {
+		fmt.Printf("fd flags:       unknown (%v)\n", err)
+		return
+	}
+
+	var parts []string
+	missing := false
+	for _, f := range fdFlags {
+		mark := "ok"
+		if !strings.Contains(string(help), f.long) {
+			mark = "missing"
+			missing = true
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", f.short, mark))
+	}
+	fmt.Printf("fd flags:       %s\n", strings.Join(parts, ", "))
+	if missing {
+		fmt.Println("                this fd is missing flags extdust needs; results may be wrong")
+	}
+}
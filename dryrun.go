@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// shellQuote quotes s for a POSIX shell when it holds anything but plain characters
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,+@%") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// printDryRun prints how each root would be scanned: the resolved path and
+// either the fd command line, the native walker or the archive reader
+func printDryRun(roots []string, opts scan.Options) {
+	if opts.FdCommand != "" {
+		fmt.Printf("fd binary: %s\n", opts.FdCommand)
+	} else {
+		fmt.Println("fd binary: none (native walker)")
+	}
+	for _, root := range roots {
+		resolved, err := filepath.Abs(root)
+		if err != nil {
+			resolved = root
+		}
+		fmt.Printf("\nPath: %s\n", resolved)
+		switch {
+		case scan.IsArchiveRoot(root):
+			fmt.Println("Scan: entries listed from the archive")
+		case opts.FdCommand != "":
+			words := []string{shellQuote(opts.FdCommand)}
+			for _, arg := range scan.FdArgs(root, opts.Filter) {
+				words = append(words, shellQuote(arg))
+			}
+			fmt.Printf("Command: %s\n", strings.Join(words, " "))
+		default:
+			fmt.Println("Scan: native walker")
+		}
+	}
+}
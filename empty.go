@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// findEmptyFiles returns the paths of all zero-byte files per extension, sorted
+func findEmptyFiles(stats *scan.Stats) (map[string][]string, error) {
+	empty := make(map[string][]string)
+	err := stats.EachFile(func(ext string, f scan.FileDetail) {
+		if f.Size == 0 {
+			empty[ext] = append(empty[ext], f.Path)
+		}
+	})
+	for _, paths := range empty {
+		sort.Strings(paths)
+	}
+	return empty, err
+}
+
+// printEmptyFiles prints the empty files grouped by extension, most files first
+func printEmptyFiles(empty map[string][]string, redactRoot string) {
+	fmt.Println("==================================")
+	fmt.Println(" Empty Files ")
+	fmt.Println("==================================")
+
+	exts := make([]string, 0, len(empty))
+	total := 0
+	for ext, paths := range empty {
+		exts = append(exts, ext)
+		total += len(paths)
+	}
+	sort.Slice(exts, func(i, j int) bool {
+		if len(empty[exts[i]]) != len(empty[exts[j]]) {
+			return len(empty[exts[i]]) > len(empty[exts[j]])
+		}
+		return exts[i] < exts[j]
+	})
+
+	for _, ext := range exts {
+		fmt.Printf("%s: %s\n", bold(extLabel(ext)), formatFileCount(len(empty[ext])))
+		for i, p := range empty[ext] {
+			prefix := "├──"
+			if i == len(empty[ext])-1 {
+				prefix = "└──"
+			}
+			fmt.Printf("%s %s\n", dim(prefix), displayPath(p, redactRoot))
+		}
+	}
+	fmt.Println("==================================")
+	fmt.Printf("%s with no content\n", formatFileCount(total))
+}
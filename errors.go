@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// printErrors lists the files and directories that could not be read, in scan order
+func printErrors(errs []scan.ScanError, redactRoot string) {
+	fmt.Println("==================================")
+	fmt.Printf(" Unreadable (%d) \n", len(errs))
+	fmt.Println("==================================")
+	for i, e := range errs {
+		prefix := "├──"
+		if i == len(errs)-1 {
+			prefix = "└──"
+		}
+		fmt.Printf("%s %s (%s)\n", dim(prefix), displayPath(e.Path, redactRoot), e.Err)
+	}
+	fmt.Println("==================================")
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/template"
+)
+
+// formatRow is what a --format template sees for each extension
+type formatRow struct {
+	Ext       string  // the extension as shown in the summary
+	Size      int64   // bytes
+	HumanSize string  // size in the current units
+	Count     int     // files
+	Percent   float64 // share of the total, 0-100
+}
+
+// formatTotals is what the --format-header and --format-footer templates see
+type formatTotals struct {
+	Size       int64
+	HumanSize  string
+	Count      int
+	Extensions int // rows printed
+}
+
+// outputFormat holds the parsed --format templates; header and footer may be nil
+type outputFormat struct {
+	row, header, footer *template.Template
+}
+
+// parseOutputFormat parses the --format templates and runs each once on zero
+// values, so unknown fields are reported before scanning
+func parseOutputFormat(row, header, footer string) (*outputFormat, error) {
+	parse := func(flag, text string, sample any) (*template.Template, error) {
+		if text == "" {
+			return nil, nil
+		}
+		t, err := template.New(flag).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", flag, err)
+		}
+		if err := t.Execute(io.Discard, sample); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", flag, err)
+		}
+		return t, nil
+	}
+
+	var f outputFormat
+	var err error
+	if f.row, err = parse("--format", row, formatRow{}); err != nil {
+		return nil, err
+	}
+	if f.header, err = parse("--format-header", header, formatTotals{}); err != nil {
+		return nil, err
+	}
+	if f.footer, err = parse("--format-footer", footer, formatTotals{}); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// print writes the header, one line per extension in summary order and the
+// footer. Percentages are of totalSize, as with --percent.
+func (f *outputFormat) print(w io.Writer, sortedExtensions []string, sizes map[string]int64, counts map[string]int, totalSize int64) error {
+	totals := formatTotals{Size: totalSize, HumanSize: formatSize(totalSize), Extensions: len(sortedExtensions)}
+	for _, ext := range sortedExtensions {
+		totals.Count += counts[ext]
+	}
+
+	line := func(t *template.Template, data any) error {
+		if t == nil {
+			return nil
+		}
+		if err := t.Execute(w, data); err != nil {
+			return fmt.Errorf("error formatting output: %w", err)
+		}
+		_, err := fmt.Fprintln(w)
+		return err
+	}
+
+	if err := line(f.header, totals); err != nil {
+		return err
+	}
+	for _, ext := range sortedExtensions {
+		row := formatRow{
+			Ext:       extLabel(ext),
+			Size:      sizes[ext],
+			HumanSize: formatSize(sizes[ext]),
+			Count:     counts[ext],
+		}
+		if totalSize > 0 {
+			row.Percent = float64(sizes[ext]) * 100 / float64(totalSize)
+		}
+		if err := line(f.row, row); err != nil {
+			return err
+		}
+	}
+	return line(f.footer, totals)
+}
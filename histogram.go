@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/awsms/extdust/pkg/scan"
+	"github.com/mattn/go-runewidth"
+)
+
+const defaultHistogramEdges = "1KiB,1MiB,100MiB"
+
+// sizeEdge is one boundary between histogram buckets, keeping the user's spelling for labels
+type sizeEdge struct {
+	label string
+	size  int64
+}
+
+// sizeBucket is the number and total size of all files within one size range
+type sizeBucket struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+	Size  int64  `json:"size"`
+}
+
+// parseHistogramEdges parses a comma-separated list of bucket edges, e.g. "1KiB,1MiB"
+func parseHistogramEdges(s string) ([]sizeEdge, error) {
+	var edges []sizeEdge
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		size, err := parseSize(part)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, sizeEdge{label: part, size: size})
+	}
+	if len(edges) == 0 {
+		return nil, fmt.Errorf("no histogram edges given")
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		return edges[i].size < edges[j].size
+	})
+	return edges, nil
+}
+
+// computeHistogram buckets every file by size. It returns len(edges)+1
+// buckets, smallest first; a file exactly at an edge goes in the bucket above.
+func computeHistogram(stats *scan.Stats, edges []sizeEdge) ([]sizeBucket, error) {
+	buckets := make([]sizeBucket, len(edges)+1)
+	buckets[0].Label = "< " + edges[0].label
+	for i := 1; i < len(edges); i++ {
+		buckets[i].Label = edges[i-1].label + " – " + edges[i].label
+	}
+	buckets[len(edges)].Label = "≥ " + edges[len(edges)-1].label
+
+	err := stats.EachFile(func(_ string, f scan.FileDetail) {
+		i := sort.Search(len(edges), func(i int) bool {
+			return f.Size < edges[i].size
+		})
+		buckets[i].Count++
+		buckets[i].Size += f.Size
+	})
+	return buckets, err
+}
+
+// printHistogram prints the buckets with a bar per file count, scaled to the fullest bucket
+func printHistogram(buckets []sizeBucket, ascii bool, width int) {
+	fmt.Println("==================================")
+	fmt.Println(" Histogram: Files by Size ")
+	fmt.Println("==================================")
+
+	labelWidth, lineWidth, largest := 0, 0, 0
+	for _, b := range buckets {
+		labelWidth = max(labelWidth, runewidth.StringWidth(b.Label)+1)
+	}
+	lines := make([]string, len(buckets))
+	for i, b := range buckets {
+		lines[i] = runewidth.FillRight(b.Label+":", labelWidth) + " " + formatFileCount(b.Count) + " (" + formatSize(b.Size) + ")"
+		lineWidth = max(lineWidth, runewidth.StringWidth(lines[i]))
+		largest = max(largest, b.Count)
+	}
+	if width <= 0 {
+		width = defaultChartWidth
+	}
+	cells := max(width-lineWidth-2, minBarCells)
+
+	for i, b := range buckets {
+		line := lines[i]
+		if bar := renderBar(int64(b.Count), int64(largest), cells, ascii); bar != "" {
+			line += strings.Repeat(" ", lineWidth-runewidth.StringWidth(line)+2) + bar
+		}
+		fmt.Println(line)
+	}
+	fmt.Println("==================================")
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"time"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// htmlRow is one extension in the HTML report
+type htmlRow struct {
+	Extension string
+	Size      int64
+	Formatted string
+	Count     int
+	Percent   float64
+	Bar       float64 // bar width in percent of the largest extension
+}
+
+// htmlData is what htmlTemplate renders
+type htmlData struct {
+	Roots          []string
+	Generated      string
+	Rows           []htmlRow
+	Total          int64
+	TotalFormatted string
+	TotalCount     int
+	Skipped        int
+}
+
+// htmlTemplate is a standalone page: styles and the column sorting script are inline
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>extdust report</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.4em; margin-bottom: 0.2em; }
+.meta { color: #666; margin: 0.2em 0; }
+table { border-collapse: collapse; margin-top: 1.5em; min-width: 40em; }
+th, td { padding: 0.35em 0.8em; border-bottom: 1px solid #ddd; text-align: left; }
+th { cursor: pointer; user-select: none; background: #f4f4f4; }
+th::after { content: " \2195"; color: #aaa; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+td.bar { width: 30%; }
+.bar div { background: #4a90d9; height: 0.9em; border-radius: 2px; }
+tfoot td { font-weight: bold; border-bottom: none; }
+</style>
+</head>
+<body>
+<h1>Storage per Extension</h1>
+<p class="meta">Scanned: {{range $i, $r := .Roots}}{{if $i}}, {{end}}<code>{{$r}}</code>{{end}}</p>
+<p class="meta">Generated: {{.Generated}}</p>
+<p class="meta">Total: {{.TotalFormatted}} in {{.TotalCount}} file(s){{if .Skipped}}; {{.Skipped}} file(s) could not be read{{end}}</p>
+<table id="report">
+<thead>
+<tr><th data-type="text">Extension</th><th data-type="num">Size</th><th data-type="num">Files</th><th data-type="num">Percent</th><th data-type="num">Share</th></tr>
+</thead>
+<tbody>
+{{range .Rows}}<tr>
+<td>{{.Extension}}</td>
+<td class="num" data-value="{{.Size}}">{{.Formatted}}</td>
+<td class="num" data-value="{{.Count}}">{{.Count}}</td>
+<td class="num" data-value="{{.Percent}}">{{printf "%.1f" .Percent}}%</td>
+<td class="bar" data-value="{{.Size}}"><div style="width: {{printf "%.2f" .Bar}}%"></div></td>
+</tr>
+{{end}}</tbody>
+<tfoot>
+<tr><td>Total</td><td class="num">{{.TotalFormatted}}</td><td class="num">{{.TotalCount}}</td><td></td><td></td></tr>
+</tfoot>
+</table>
+<script>
+document.querySelectorAll("#report th").forEach(function (th, col) {
+  var asc = false;
+  th.addEventListener("click", function () {
+    var body = document.querySelector("#report tbody");
+    var rows = Array.prototype.slice.call(body.rows);
+    asc = !asc;
+    rows.sort(function (a, b) {
+      var x = a.cells[col], y = b.cells[col], d;
+      if (th.dataset.type === "num") {
+        d = parseFloat(x.dataset.value) - parseFloat(y.dataset.value);
+      } else {
+        d = x.textContent.localeCompare(y.textContent);
+      }
+      return asc ? d : -d;
+    });
+    rows.forEach(function (r) { body.appendChild(r); });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// writeHTML writes a self-contained HTML report of the summary to target,
+// with one sortable row per extension in summary order. Percentages are of
+// totalSize, as with --percent.
+func writeHTML(target string, roots []string, sortedExtensions []string, stats *scan.Stats, totalSize int64, now time.Time) error {
+	data := htmlData{
+		Roots:          roots,
+		Generated:      now.Format(time.RFC1123),
+		Total:          totalSize,
+		TotalFormatted: formatSize(totalSize),
+		Skipped:        stats.Skipped,
+	}
+	var largest int64
+	for _, ext := range sortedExtensions {
+		largest = max(largest, stats.Sizes[ext])
+	}
+	for _, ext := range sortedExtensions {
+		size := stats.Sizes[ext]
+		row := htmlRow{
+			Extension: extLabel(ext),
+			Size:      size,
+			Formatted: formatSize(size),
+			Count:     stats.Counts[ext],
+		}
+		if totalSize > 0 {
+			row.Percent = float64(size) * 100 / float64(totalSize)
+		}
+		if largest > 0 {
+			row.Bar = float64(size) * 100 / float64(largest)
+		}
+		data.Rows = append(data.Rows, row)
+		data.TotalCount += row.Count
+	}
+
+	f, err := os.Create(target)
+	if err != nil {
+		return fmt.Errorf("error creating HTML file: %w", err)
+	}
+	defer f.Close()
+	if err := htmlTemplate.Execute(f, data); err != nil {
+		return fmt.Errorf("error writing HTML: %w", err)
+	}
+	return nil
+}
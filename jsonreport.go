@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+type jsonFile struct {
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	Formatted string    `json:"formatted"`
+	ModTime   time.Time `json:"mod_time"`
+}
+
+type jsonFolder struct {
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Formatted string `json:"formatted"`
+}
+
+type jsonExtension struct {
+	Extension string       `json:"extension"`
+	Size      int64        `json:"size"`
+	Formatted string       `json:"formatted"`
+	FileCount int          `json:"file_count"`
+	Files     []jsonFile   `json:"files"`
+	Folders   []jsonFolder `json:"folders"`
+	SizeStats *sizeStats   `json:"size_stats,omitempty"`
+	Times     *timeRange   `json:"times,omitempty"`
+}
+
+type jsonViolation struct {
+	Extension string `json:"extension"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+}
+
+// jsonReport is the document written by --json. Sizes are raw byte counts;
+// the "formatted" fields carry the same human strings as the text view.
+type jsonReport struct {
+	Extensions     []jsonExtension     `json:"extensions"`
+	Total          int64               `json:"total"`
+	TotalFormatted string              `json:"total_formatted"`
+	Skipped        int                 `json:"skipped"`
+	Errors         []scan.ScanError    `json:"errors,omitempty"`
+	Biggest        []jsonFile          `json:"biggest,omitempty"`
+	HotFolders     []jsonFolder        `json:"hot_folders,omitempty"`
+	AgeBands       []ageBand           `json:"age_bands,omitempty"`
+	Histogram      []sizeBucket        `json:"histogram,omitempty"`
+	Tree           []*treeNode         `json:"tree,omitempty"`
+	Cooccurrence   []extPair           `json:"cooccurrence,omitempty"`
+	Violations     []jsonViolation     `json:"allowlist_violations,omitempty"`
+	EmptyFiles     map[string][]string `json:"empty_files,omitempty"`
+	Duplicates     *dupReport          `json:"duplicates,omitempty"`
+	Diff           []extDelta          `json:"diff,omitempty"`
+}
+
+// buildJSONReport converts stats into the --json document, in summary order.
+// File and folder arrays are ordered like the text view and cut at fileLimit
+// and dirLimit.
+func buildJSONReport(sortedExtensions []string, stats *scan.Stats, totalSize int64, fileLimit, dirLimit int, fileLess, dirLess func(a, b scan.FileDetail) bool, redactRoot string) jsonReport {
+	report := jsonReport{
+		Extensions:     []jsonExtension{},
+		Total:          totalSize,
+		TotalFormatted: formatSize(totalSize),
+		Skipped:        stats.Skipped,
+	}
+
+	for _, e := range stats.Errors {
+		report.Errors = append(report.Errors, scan.ScanError{Path: displayPath(e.Path, redactRoot), Err: e.Err})
+	}
+
+	for _, ext := range sortedExtensions {
+		files := sortedFiles(stats.Files[ext], fileLess)
+
+		entry := jsonExtension{
+			Extension: ext,
+			Size:      stats.Sizes[ext],
+			Formatted: formatSize(stats.Sizes[ext]),
+			FileCount: stats.Counts[ext],
+			Files:     []jsonFile{},
+			Folders:   []jsonFolder{},
+		}
+		for i, f := range files {
+			if i == fileLimit {
+				break
+			}
+			entry.Files = append(entry.Files, jsonFile{
+				Path:      displayPath(f.Path, redactRoot),
+				Size:      f.Size,
+				Formatted: formatSize(f.Size),
+				ModTime:   f.ModTime,
+			})
+		}
+		for i, f := range sortedFolders(stats.Folders[ext], dirLess) {
+			if i == dirLimit {
+				break
+			}
+			entry.Folders = append(entry.Folders, jsonFolder{
+				Path:      displayPath(f.Path, redactRoot),
+				Size:      f.Size,
+				Formatted: formatSize(f.Size),
+			})
+		}
+
+		report.Extensions = append(report.Extensions, entry)
+	}
+	return report
+}
+
+// writeJSON writes v to stdout as indented JSON
+func writeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
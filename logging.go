@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// logger receives the diagnostic messages: what the scan does, unreadable
+// files and cache problems. It writes to stderr only, so the report on stdout
+// stays clean; by default only warnings and errors are shown.
+var logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+// setupLogging replaces logger according to --log-format and --log-level.
+// Without an explicit level, each -v lowers it by one step: -v shows info
+// messages and -vv debug messages.
+func setupLogging(format, level string, verbosity int) error {
+	lvl := slog.LevelWarn
+	switch {
+	case level != "":
+		if err := lvl.UnmarshalText([]byte(level)); err != nil {
+			return fmt.Errorf("invalid --log-level %q: must be debug, info, warn or error", level)
+		}
+	case verbosity >= 2:
+		lvl = slog.LevelDebug
+	case verbosity == 1:
+		lvl = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	switch strings.ToLower(format) {
+	case "", "text":
+		logger = slog.New(slog.NewTextHandler(os.Stderr, opts))
+	case "json":
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, opts))
+	default:
+		return fmt.Errorf("invalid --log-format %q: must be text or json", format)
+	}
+	return nil
+}
@@ -1,228 +1,416 @@
 package main
 
 import (
-	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"math"
 	"os"
-	"os/exec"
+	"os/signal"
+	"path"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
-	"unicode"
+	"syscall"
+	"time"
 
+	"github.com/awsms/extdust/pkg/scan"
+	"github.com/mattn/go-runewidth"
 	"github.com/spf13/cobra"
 )
 
-type FileDetail struct {
-	Path string
-	Size int64
-}
+// Exit codes
+const (
+	exitError       = 1   // bad arguments, scan failures, or a failed gate (--max-total, --allowlist)
+	exitNoFiles     = 2   // the scan succeeded but matched no files
+	exitPartial     = 3   // --timeout cut the scan short; partial results were printed
+	exitInterrupted = 130 // Ctrl-C
+)
 
-type ExtensionStats struct {
-	Sizes   map[string]int64
-	Files   map[string][]FileDetail
-	Folders map[string]map[string]int64
-}
+// alignSizes pads formatSize output so decimal points line up in a column
+// (set from --align-sizes)
+var alignSizes bool
 
-func newExtensionStats() *ExtensionStats {
-	return &ExtensionStats{
-		Sizes:   make(map[string]int64),
-		Files:   make(map[string][]FileDetail),
-		Folders: make(map[string]map[string]int64),
+// siUnits switches formatSize and parseSize to decimal units (set from --si)
+var siUnits bool
+
+// rawBytes makes formatSize print plain byte counts (set from --bytes)
+var rawBytes bool
+
+// formatSize renders a size for display, honouring --bytes, --align-sizes and --si
+func formatSize(size int64) string {
+	if rawBytes {
+		return formatCount(size)
 	}
+	return scan.FormatSizeAs(size, siUnits, alignSizes)
 }
 
-func formatSize(size int64) string {
-	const (
-		KB = 1024
-		MB = KB * 1024
-		GB = MB * 1024
-		TB = GB * 1024
-	)
-
-	switch {
-	case size >= TB:
-		return fmt.Sprintf("%.2f TB", float64(size)/float64(TB))
-	case size >= GB:
-		return fmt.Sprintf("%.2f GB", float64(size)/float64(GB))
-	case size >= MB:
-		return fmt.Sprintf("%.2f MB", float64(size)/float64(MB))
-	case size >= KB:
-		return fmt.Sprintf("%.2f KB", float64(size)/float64(KB))
-	default:
-		return fmt.Sprintf("%d bytes", size)
+// caseSensitive keeps extension keys as found instead of lower-casing them,
+// and then also shows them as-is instead of upper-cased (set from --case-sensitive)
+var caseSensitive bool
+
+// byOwner keys files by owning user (set from --by-owner); user names are
+// shown as they are
+var byOwner bool
+
+// extLabel returns how an extension key is shown in text output
+func extLabel(ext string) string {
+	if caseSensitive || byOwner {
+		return ext
 	}
+	return strings.ToUpper(ext)
 }
 
-func findExecutable(names ...string) (string, error) {
-	for _, name := range names {
-		path, err := exec.LookPath(name)
-		if err == nil {
-			return path, nil
-		}
+// formatFileCount renders a file count with the right plural, e.g. "1 file", "34 files"
+func formatFileCount(n int) string {
+	if n == 1 {
+		return "1 file"
 	}
-	return "", fmt.Errorf("none of the executables were found")
+	return formatCount(int64(n)) + " files"
 }
 
-func isStandardExtension(ext string) bool {
-	if len(ext) > 4 {
-		return false
+// parseSize converts a human size like "1.5GB", "500K", "10MiB" or "1024" back
+// into bytes. Units are case-insensitive. "KiB" is always 1024 bytes; "KB"
+// and "K" are 1024 bytes too, or 1000 with --si to match formatSize. A bare
+// number is a byte count.
+func parseSize(s string) (int64, error) {
+	factors := map[string]float64{
+		"":  1,
+		"B": 1,
+		"K": 1 << 10,
+		"M": 1 << 20,
+		"G": 1 << 30,
+		"T": 1 << 40,
 	}
-	hasLetter := false
-	for _, r := range ext {
-		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
-			return false
-		}
-		if unicode.IsLetter(r) {
-			hasLetter = true
-		}
+	decimalFactors := map[string]float64{
+		"":  1,
+		"B": 1,
+		"K": 1e3,
+		"M": 1e6,
+		"G": 1e9,
+		"T": 1e12,
+	}
+
+	value := strings.TrimSpace(s)
+	if value == "" {
+		return 0, fmt.Errorf("invalid size %q: empty value", s)
+	}
+
+	// split into leading number and trailing unit
+	i := 0
+	for i < len(value) && (value[i] >= '0' && value[i] <= '9' || value[i] == '.') {
+		i++
+	}
+	number, unit := value[:i], strings.ToUpper(strings.TrimSpace(value[i:]))
+	if number == "" {
+		return 0, fmt.Errorf("invalid size %q: missing number", s)
 	}
-	return hasLetter
+
+	// accept K, KB and KiB (and likewise for the other prefixes)
+	binary := strings.HasSuffix(unit, "IB")
+	unit = strings.TrimSuffix(unit, "IB")
+	if len(unit) == 2 && unit[1] == 'B' {
+		unit = unit[:1]
+	}
+	if siUnits && !binary {
+		factors = decimalFactors
+	}
+	factor, ok := factors[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q", s, strings.TrimSpace(value[i:]))
+	}
+
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: bad number %q", s, number)
+	}
+	bytes := n * factor
+	if bytes > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q: value too large", s)
+	}
+	return int64(bytes), nil
+}
+
+// sortedFiles returns a sorted copy of files, leaving the slice in Stats as it was
+func sortedFiles(files []scan.FileDetail, less func(a, b scan.FileDetail) bool) []scan.FileDetail {
+	sorted := append([]scan.FileDetail(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+// sortedFolders flattens one extension's folder map into a list ordered by less
+func sortedFolders(folders map[string]int64, less func(a, b scan.FileDetail) bool) []scan.FileDetail {
+	folderList := make([]scan.FileDetail, 0, len(folders))
+	for folder, fsize := range folders {
+		folderList = append(folderList, scan.FileDetail{Path: folder, Size: fsize})
+	}
+	return sortedFiles(folderList, less)
 }
 
-// buildFdArgs builds the argument list for fdfind
-func buildFdArgs(path, extensions string) []string {
-	// always search all files, possibly narrowed by -e
-	args := []string{"--type", "f", "-H", "-I", "--full-path", "--base-directory", path}
+// sectionOrders are the values of --file-sort and --dir-sort
+var sectionOrders = []string{"size-asc", "size-desc", "name"}
 
-	if extensions == "" {
-		return args
+// sectionLess returns the order of one detail section: the --file-sort or
+// --dir-sort value when set, otherwise the shared detailSort and -s direction
+func sectionLess(order, detailSort string, reverseSize bool) func(a, b scan.FileDetail) bool {
+	switch order {
+	case "size-asc":
+		return scan.FileLess("size", true)
+	case "size-desc":
+		return scan.FileLess("size", false)
+	case "name":
+		return scan.FileLess("name", false)
 	}
+	return scan.FileLess(detailSort, reverseSize)
+}
 
-	extensionList := strings.Split(extensions, ",")
-	for _, ext := range extensionList {
-		ext = strings.TrimSpace(ext)
-		if ext != "" {
-			args = append(args, "-e", ext)
+// resolveDetailSort returns the file order of the --files view from
+// --detail-sort (explicit when set is true) and --sort-time, which is the
+// same as --detail-sort mtime
+func resolveDetailSort(detailSort string, set, sortTime bool) (string, error) {
+	if sortTime {
+		if set && detailSort != "mtime" {
+			return "", fmt.Errorf("--sort-time cannot be combined with --detail-sort %s", detailSort)
 		}
+		detailSort = "mtime"
+	}
+	if detailSort != "size" && detailSort != "mtime" {
+		return "", fmt.Errorf("invalid --detail-sort %q: must be size or mtime", detailSort)
 	}
-	return args
+	return detailSort, nil
 }
 
-// scanFiles runs fdfind and fills ExtensionStats
-func scanFiles(fdCmdName, path string, stats *ExtensionStats, cmdArgs []string) error {
-	fdCmd := exec.Command(fdCmdName, cmdArgs...)
+// filterMinSize drops extensions whose aggregate size is below minSize, keeping order
+func filterMinSize(exts []string, sizes map[string]int64, minSize int64) []string {
+	if minSize <= 0 {
+		return exts
+	}
+	kept := exts[:0]
+	for _, ext := range exts {
+		if sizes[ext] >= minSize {
+			kept = append(kept, ext)
+		}
+	}
+	return kept
+}
 
-	stdout, err := fdCmd.StdoutPipe()
-	if err != nil {
-		return fmt.Errorf("error obtaining stdout: %w", err)
+// filterMinCount drops extensions with fewer than minCount files, keeping order
+func filterMinCount(exts []string, counts map[string]int, minCount int) []string {
+	if minCount <= 0 {
+		return exts
 	}
-	stderr, err := fdCmd.StderrPipe()
-	if err != nil {
-		return fmt.Errorf("error obtaining stderr: %w", err)
+	kept := exts[:0]
+	for _, ext := range exts {
+		if counts[ext] >= minCount {
+			kept = append(kept, ext)
+		}
 	}
+	return kept
+}
 
-	if err := fdCmd.Start(); err != nil {
-		return fmt.Errorf("error starting command: %w", err)
+// collapseToTop keeps the n largest of exts and folds the rest into a single
+// label bucket in stats, which is appended after the kept extensions.
+// It is a no-op when n <= 0 or there are no more than n extensions.
+func collapseToTop(stats *scan.Stats, exts []string, n int, label string) []string {
+	if n <= 0 || len(exts) <= n {
+		return exts
 	}
 
-	// logs fdfind stderr in a goroutine
-	go func() {
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
-			fmt.Printf("fd error output: %s\n", scanner.Text())
-		}
-	}()
-
-	scanner := bufio.NewScanner(stdout)
-	for scanner.Scan() {
-		relativePath := scanner.Text()
-		filePath := filepath.Join(path, relativePath)
-		info, err := os.Stat(filePath)
-		if err != nil {
-			fmt.Printf("Error statting file %s: %v\n", filePath, err)
+	bySize := append([]string(nil), exts...)
+	sort.SliceStable(bySize, func(i, j int) bool {
+		return stats.Sizes[bySize[i]] > stats.Sizes[bySize[j]]
+	})
+	keep := make(map[string]bool, n)
+	for _, ext := range bySize[:n] {
+		keep[ext] = true
+	}
+
+	var kept []string
+	for _, ext := range exts {
+		if keep[ext] {
+			kept = append(kept, ext)
 			continue
 		}
-
-		fileExt := strings.ToLower(filepath.Ext(filePath))
-		if fileExt == "" {
-			fileExt = "no extension"
-		} else {
-			fileExt = fileExt[1:] // remove the dot
-			if !isStandardExtension(fileExt) {
-				fileExt = "no extension"
-			}
+		if ext == label {
+			continue // already the bucket; its data stays in place
 		}
 
-		fileSize := info.Size()
-		stats.Sizes[fileExt] += fileSize
-		stats.Files[fileExt] = append(stats.Files[fileExt], FileDetail{Path: filePath, Size: fileSize})
+		stats.Sizes[label] += stats.Sizes[ext]
+		stats.Counts[label] += stats.Counts[ext]
+		stats.Files[label] = append(stats.Files[label], stats.Files[ext]...)
+		if stats.Folders[label] == nil {
+			stats.Folders[label] = make(map[string]int64)
+		}
+		for dir, size := range stats.Folders[ext] {
+			stats.Folders[label][dir] += size
+		}
+		delete(stats.Sizes, ext)
+		delete(stats.Counts, ext)
+		delete(stats.Files, ext)
+		delete(stats.Folders, ext)
+	}
+	if !keep[label] {
+		kept = append(kept, label)
+	}
+	return kept
+}
 
-		dir := filepath.Dir(filePath)
-		if _, exists := stats.Folders[fileExt]; !exists {
-			stats.Folders[fileExt] = make(map[string]int64)
+// failExtensions turns the --fail-ext list into stats keys, applying the
+// --merge aliases; nil means the total is checked
+func failExtensions(list string, merges map[string]string) []string {
+	var exts []string
+	for _, ext := range strings.Split(list, ",") {
+		ext = strings.TrimPrefix(strings.TrimSpace(ext), ".")
+		if ext == "" {
+			continue
 		}
-		stats.Folders[fileExt][dir] += fileSize
+		if !caseSensitive {
+			ext = strings.ToLower(ext)
+		}
+		exts = append(exts, mergeOf(merges)(ext))
 	}
+	return exts
+}
 
-	if err := fdCmd.Wait(); err != nil {
-		return fmt.Errorf("command execution failed: %w", err)
+// overLimit returns a message for each of exts whose size is above limit,
+// or for the total of every extension when exts is empty
+func overLimit(sizes map[string]int64, exts []string, limit int64) []string {
+	var msgs []string
+	if len(exts) == 0 {
+		var totalSize int64
+		for _, size := range sizes {
+			totalSize += size
+		}
+		if totalSize > limit {
+			msgs = append(msgs, fmt.Sprintf("Total %s exceeds --fail-if-over %s by %s",
+				formatSize(totalSize), formatSize(limit), formatSize(totalSize-limit)))
+		}
+		return msgs
 	}
+	for _, ext := range exts {
+		if size := sizes[ext]; size > limit {
+			msgs = append(msgs, fmt.Sprintf("%s %s exceeds --fail-if-over %s by %s",
+				extLabel(ext), formatSize(size), formatSize(limit), formatSize(size-limit)))
+		}
+	}
+	return msgs
+}
+
+// totalMode is the value of --total: "" (no total line), "filtered" (the
+// extensions shown) or "all" (every extension found, including those hidden by
+// --min-size, --top and the like). "true" and "false" are accepted for
+// environment variables and config files written for the old boolean flag.
+type totalMode string
 
+func (m *totalMode) String() string { return string(*m) }
+func (m *totalMode) Type() string   { return "mode" }
+
+func (m *totalMode) Set(v string) error {
+	switch v {
+	case "filtered", "all":
+		*m = totalMode(v)
+	case "true":
+		*m = "filtered"
+	case "false", "":
+		*m = ""
+	default:
+		return fmt.Errorf("must be filtered or all")
+	}
 	return nil
 }
 
-// collectSortedExtensions returns the list of known extensions, sorted according to flags
-func collectSortedExtensions(sizes map[string]int64, sortName, reverseSize bool) []string {
-	var exts []string
-	for ext := range sizes {
-		exts = append(exts, ext)
+// summaryTotal is the total shown under the summary: the extensions in exts,
+// or with unfiltered every extension in stats
+func summaryTotal(stats *scan.Stats, exts []string, unfiltered bool) int64 {
+	var totalSize int64
+	if unfiltered {
+		for _, size := range stats.Sizes {
+			totalSize += size
+		}
+		return totalSize
 	}
-
-	if sortName {
-		sort.Strings(exts)
-		return exts
+	for _, ext := range exts {
+		totalSize += stats.Sizes[ext]
 	}
+	return totalSize
+}
 
-	if reverseSize {
-		// smallest first
-		sort.Slice(exts, func(i, j int) bool {
-			return sizes[exts[i]] < sizes[exts[j]]
-		})
-	} else {
-		// default = largest first
-		sort.Slice(exts, func(i, j int) bool {
-			return sizes[exts[i]] > sizes[exts[j]]
-		})
+// displayPath rewrites p relative to root with a leading "./" marker.
+// An empty root leaves p untouched.
+func displayPath(p, root string) string {
+	if root == "" {
+		return p
 	}
-
-	return exts
+	rel, err := filepath.Rel(root, p)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return p
+	}
+	if rel == "." {
+		return "."
+	}
+	return "." + string(filepath.Separator) + rel
 }
 
-// printDetails prints the per-extension "Storage Usage Per Extension" block
-func printDetails(sortedExtensions []string, stats *ExtensionStats, detail, folderDetail bool, limit int, reverseSize bool) {
-	if !detail && !folderDetail {
+// printDetails prints the per-extension "Storage Usage Per Extension" block.
+// With width > 0, long paths are shortened so each line fits in width columns.
+// summary, when not nil, adds a size distribution line under each extension header
+func printDetails(sortedExtensions []string, stats *scan.Stats, detail, folderDetail bool, summary map[string]sizeStats, times map[string]timeRange, fileLimit, dirLimit int, fileLess, dirLess func(a, b scan.FileDetail) bool, onlyWithFiles bool, redactRoot string, width int) {
+	if !detail && !folderDetail && summary == nil && times == nil {
 		return
 	}
 
+	// extensions with a size but no file list (e.g. dropped by a filter
+	// after the scan) stay in the summary; here they are left out with
+	// onlyWithFiles, and otherwise labelled
+	if onlyWithFiles {
+		var listed []string
+		for _, ext := range sortedExtensions {
+			if len(stats.Files[ext]) > 0 {
+				listed = append(listed, ext)
+			}
+		}
+		sortedExtensions = listed
+	}
+
 	fmt.Println("Storage Usage Per Extension:")
 	for i, ext := range sortedExtensions {
 		files := stats.Files[ext]
 		size, exists := stats.Sizes[ext]
 		if !exists || len(files) == 0 {
-			fmt.Printf("%s: No files found.\n", strings.ToUpper(ext))
+			if exists {
+				fmt.Printf("%s: %s %s\n", bold(extLabel(ext)), colorSize(size), dim("(no files listed)"))
+			} else {
+				fmt.Printf("%s: No files found.\n", bold(extLabel(ext)))
+			}
+			if i < len(sortedExtensions)-1 {
+				fmt.Println("_____________")
+				fmt.Println()
+			}
 			continue
 		}
 
-		fmt.Printf("%s: %s\n", strings.ToUpper(ext), formatSize(size))
+		fmt.Printf("%s: %s\n", bold(extLabel(ext)), colorSize(size))
+		if s, ok := summary[ext]; ok {
+			fmt.Println(dim(formatSizeStats(s)))
+		}
+		if r, ok := times[ext]; ok {
+			fmt.Println(dim(formatTimeRange(r, time.Now())))
+		}
 
 		if detail {
-			// sort files by size in the same direction as summary
-			if reverseSize {
-				// -s = smallest first
-				sort.Slice(files, func(i, j int) bool {
-					return files[i].Size < files[j].Size
-				})
-			} else {
-				// default = largest first
-				sort.Slice(files, func(i, j int) bool {
-					return files[i].Size > files[j].Size
-				})
-			}
+			files = sortedFiles(files, fileLess)
 
 			fileCount := len(files)
-			displayLimit := limit
-			if fileCount < limit {
+			displayLimit := fileLimit
+			if fileCount < fileLimit {
 				displayLimit = fileCount
 			}
 			for i := 0; i < displayLimit; i++ {
@@ -230,31 +418,21 @@ func printDetails(sortedExtensions []string, stats *ExtensionStats, detail, fold
 				if i == displayLimit-1 {
 					prefix = "└──"
 				}
-				fmt.Printf("%s %s (%s)\n", prefix, files[i].Path, formatSize(files[i].Size))
+				// fit using the plain suffix; color codes take no columns
+				suffix := " (" + formatSize(files[i].Size) + ")"
+				p := fitPath(prefix+" ", displayPath(files[i].Path, redactRoot), suffix, width)
+				fmt.Printf("%s %s (%s)\n", dim(prefix), p, colorSize(files[i].Size))
 			}
 		}
 
-		if folderDetail {
+		// a section header with nothing under it is left out
+		if folderDetail && len(stats.Folders[ext]) > 0 {
 			fmt.Println("\nFolders:")
-			folders := stats.Folders[ext]
-			folderList := make([]FileDetail, 0, len(folders))
-			for folder, fsize := range folders {
-				folderList = append(folderList, FileDetail{Path: folder, Size: fsize})
-			}
-
-			if reverseSize {
-				sort.Slice(folderList, func(i, j int) bool {
-					return folderList[i].Size < folderList[j].Size
-				})
-			} else {
-				sort.Slice(folderList, func(i, j int) bool {
-					return folderList[i].Size > folderList[j].Size
-				})
-			}
+			folderList := sortedFolders(stats.Folders[ext], dirLess)
 
 			folderCount := len(folderList)
-			folderDisplayLimit := limit
-			if folderCount < limit {
+			folderDisplayLimit := dirLimit
+			if folderCount < dirLimit {
 				folderDisplayLimit = folderCount
 			}
 			for i := 0; i < folderDisplayLimit; i++ {
@@ -262,111 +440,1229 @@ func printDetails(sortedExtensions []string, stats *ExtensionStats, detail, fold
 				if i == folderDisplayLimit-1 {
 					prefix = "└──"
 				}
-				fmt.Printf("%s %s (%s)\n", prefix, folderList[i].Path, formatSize(folderList[i].Size))
+				suffix := " (" + formatSize(folderList[i].Size) + ")"
+				p := fitPath(prefix+" ", displayPath(folderList[i].Path, redactRoot), suffix, width)
+				fmt.Printf("%s %s (%s)\n", dim(prefix), p, colorSize(folderList[i].Size))
 			}
 		}
 
-		if i < len(sortedExtensions)-1 && (detail || folderDetail) {
+		if i < len(sortedExtensions)-1 {
 			fmt.Println("_____________")
 			fmt.Println()
 		}
 	}
 }
 
-// printSummary prints the final summary block (always printed if there are any files)
-func printSummary(sortedExtensions []string, sizes map[string]int64, total bool) {
+// printSummary prints the final summary block (always printed if there are any files).
+// totalSize is the figure for the Total line and the --percent column.
+// With chart, a bar follows each line, sized to fit width columns.
+func printSummary(sortedExtensions []string, sizes map[string]int64, counts map[string]int, totalSize int64, total, showCount, showPercent, chart, ascii bool, width int) {
 	fmt.Println("==================================")
 	fmt.Println(" Summary: Storage per Extension ")
 	fmt.Println("==================================")
+	// with aligned sizes, also pad the labels so the sizes form a column
+	labelWidth := 0
+	if alignSizes {
+		for _, ext := range sortedExtensions {
+			labelWidth = max(labelWidth, len(ext)+1)
+		}
+	}
+	// lines are laid out plain first, so bars can start in one column
+	type summaryLine struct {
+		label, extras string
+		size          int64
+	}
+	var lines []summaryLine
+	lineWidth := 0
+	var largest int64
 	for _, ext := range sortedExtensions {
-		fmt.Printf("%s: %s\n", strings.ToUpper(ext), formatSize(sizes[ext]))
+		var extras []string
+		if showCount {
+			extras = append(extras, formatFileCount(counts[ext]))
+		}
+		if showPercent {
+			percent := 0.0
+			if totalSize > 0 {
+				percent = float64(sizes[ext]) * 100 / float64(totalSize)
+			}
+			extras = append(extras, fmt.Sprintf("%.1f%%", percent))
+		}
+
+		l := summaryLine{label: fmt.Sprintf("%-*s", labelWidth, extLabel(ext)+":"), size: sizes[ext]}
+		if len(extras) > 0 {
+			l.extras = " (" + strings.Join(extras, ", ") + ")"
+		}
+		lines = append(lines, l)
+		lineWidth = max(lineWidth, runewidth.StringWidth(l.label+" "+formatSize(l.size)+l.extras))
+		largest = max(largest, l.size)
+	}
+
+	// bars are scaled to the largest extension, or to the total with
+	// --percent so that bar length and percentage agree
+	scale := largest
+	if showPercent {
+		scale = totalSize
+	}
+	if width <= 0 {
+		width = defaultChartWidth
+	}
+	cells := max(width-lineWidth-2, minBarCells)
+
+	for _, l := range lines {
+		// pad before styling so escape codes don't count towards the width
+		line := fmt.Sprintf("%s %s%s", bold(l.label), colorSize(l.size), l.extras)
+		if chart {
+			plain := runewidth.StringWidth(l.label + " " + formatSize(l.size) + l.extras)
+			line += strings.Repeat(" ", lineWidth-plain+2) + renderBar(l.size, scale, cells, ascii)
+		}
+		fmt.Println(line)
 	}
 	fmt.Println("==================================")
 
 	if total {
-		var totalSize int64
-		for _, size := range sizes {
-			totalSize += size
-		}
-		fmt.Printf("Total : %s\n", formatSize(totalSize))
+		fmt.Printf("%s %s\n", bold(fmt.Sprintf("%-*s", labelWidth, "Total :")), colorSize(totalSize))
 	}
 }
 
 func main() {
-	var path string
+	var paths []string
+	var fromStdin bool
+	var maxExtLen int
+	var keepAllExt bool
+	var splitUnusual bool
+	var fromFile string
 	var extensions string
+	var extRegex string
+	var nameGlobs []string
+	var owner string
 	var detail bool
 	var folderDetail bool
 	var limit int
+	var fileLimit int
+	var dirLimit int
 	var sortName bool
 	var reverseSize bool
 	var total bool
+	var maxTotal string
+	var failIfOver, failExt string
+	var quietErrors bool
+	var showErrors bool
+	var quiet bool
+	var detailSort string
+	var sortTime bool
+	var fileSort, dirSort string
+	var onlyWithFiles bool
+	var noConfirm bool
+	var confirmThreshold int
+	var ageBands bool
+	var redactRoot bool
+	var absolute bool
+	var maxWidth int
+	var colorMode string
+	var chart bool
+	var categories bool
+	var categoriesFile string
+	var summaryTree bool
+	var configFile string
+	var maxDepth int
+	var followSymlinks bool
+	var hardlinksOnce bool
+	var diskUsage bool
+	var ascii bool
+	var spill bool
+	var allowlist string
+	var glob bool
+	var cooccurrence bool
+	var dedupe bool
+	var showEmpty bool
+	var showStats bool
+	var showTimes bool
+	var jsonOutput bool
+	var markdown bool
+	var timing bool
+	var verbosity int
+	var logFormat, logLevel string
+	var locale string
+	var loadedConfig string
+	var dryRun bool
+	var useCache bool
+	var rowFormat, headerFormat, footerFormat string
+	var csvOutput string
+	var htmlOutput string
+	var diffFile string
+	var watch bool
+	var interactive bool
+	var interval time.Duration
+	var engine string
+	var showCount bool
+	var sortCount bool
+	var showPercent bool
+	var minSize string
+	var minCount int
+	var totalUnfiltered bool
+	var totalFlag totalMode
+	var top int
+	var otherLabel string
+	var jobs int
+	var noProgress bool
+	var byMIME bool
+	var excludes []string
+	var gitignore bool
+	var respectIgnore bool
+	var gitignoreInRepos bool
+	var noHidden bool
+	var modifiedBefore string
+	var modifiedAfter string
+	var biggest int
+	var mergeSpecs []string
+	var noDefaultMerges bool
+	var hot int
+	var timeout time.Duration
+	var checksumManifest bool
+	var manifestSizes bool
+	var output string
+	var hashAlgorithm string
+	var spillBudget int
+	var ageBandEdges string
+	var histogram bool
+	var showTree bool
+	var histogramEdges string
 
 	rootCmd := &cobra.Command{
-		Use:   "extdust",
+		Use:   "extdust [path...]",
 		Short: "Search for files with specific extensions and calculate total size per extension",
 		Long:  `A simple CLI tool to search for files with given extensions starting from a specified path and display their total size per extension, with optional file or folder details.`,
+		Args:  cobra.ArbitraryArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// precedence: flags > EXTDUST_* variables > config file > built-in
+			// defaults; each step only sets flags that are still unset
+			if err := applyEnv(cmd.Flags()); err != nil {
+				return err
+			}
+			file, err := findConfigFile(configFile)
+			if err != nil || file == "" {
+				return err
+			}
+			if err := applyConfig(cmd.Flags(), file); err != nil {
+				return err
+			}
+			// logged once logging is set up
+			loadedConfig = file
+			return nil
+		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if path == "" {
+			if err := setupLogging(logFormat, logLevel, verbosity); err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+			if loadedConfig != "" {
+				logger.Debug("read defaults from config file", "file", loadedConfig)
+			}
+			if err := setLocale(locale); err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+
+			// -p and positional arguments are combined
+			paths = append(paths, args...)
+			if len(paths) == 0 {
 				p, err := os.Getwd()
 				if err != nil {
 					fmt.Printf("Error getting current directory: %v\n", err)
-					os.Exit(1)
+					os.Exit(exitError)
 				}
-				path = p
+				paths = []string{p}
 			}
 
-			fdCmdName, err := findExecutable("fd", "fdfind")
-			if err != nil {
-				fmt.Println("Failed to find fdfind on your system. Please ensure it has been installed, and is in your PATH.")
-				os.Exit(1)
+			total = totalFlag != ""
+			if totalFlag == "all" {
+				totalUnfiltered = true
+			}
+
+			// --file-limit and --dir-limit fall back to --limit
+			if fileLimit <= 0 {
+				fileLimit = limit
+			}
+			if dirLimit <= 0 {
+				dirLimit = limit
 			}
 
-			stats := newExtensionStats()
-			cmdArgs := buildFdArgs(path, extensions)
+			if engine != "auto" && engine != "fd" && engine != "native" {
+				fmt.Printf("Invalid --engine %q: must be auto, fd or native\n", engine)
+				os.Exit(exitError)
+			}
 
-			if err := scanFiles(fdCmdName, path, stats, cmdArgs); err != nil {
+			c, err := resolveColor(colorMode)
+			if err != nil {
 				fmt.Println(err)
-				os.Exit(1)
+				os.Exit(exitError)
 			}
+			useColor = c
 
-			// collect all extensions we saw
-			if len(stats.Sizes) == 0 {
-				fmt.Println("No files found.")
+			detailSort, err = resolveDetailSort(detailSort, cmd.Flags().Changed("detail-sort"), sortTime)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(exitError)
+			}
+			for _, f := range []struct{ flag, order string }{{"--file-sort", fileSort}, {"--dir-sort", dirSort}} {
+				if f.order != "" && !slices.Contains(sectionOrders, f.order) {
+					fmt.Printf("Invalid %s %q: must be one of %s\n", f.flag, f.order, strings.Join(sectionOrders, ", "))
+					os.Exit(exitError)
+				}
+			}
+			fileLess := sectionLess(fileSort, detailSort, reverseSize)
+			dirLess := sectionLess(dirSort, "size", reverseSize)
+
+			var edges []ageEdge
+			if ageBands {
+				e, err := parseAgeBands(ageBandEdges)
+				if err != nil {
+					fmt.Printf("Invalid --age-band-edges: %v\n", err)
+					os.Exit(exitError)
+				}
+				edges = e
+			}
+
+			var sizeEdges []sizeEdge
+			if histogram {
+				e, err := parseHistogramEdges(histogramEdges)
+				if err != nil {
+					fmt.Printf("Invalid --histogram-edges: %v\n", err)
+					os.Exit(exitError)
+				}
+				sizeEdges = e
+			}
+
+			if byOwner {
+				if byMIME || categories || categoriesFile != "" || summaryTree {
+					fmt.Println("--by-owner cannot be combined with --by-mime, --categories or --summary-as-tree")
+					os.Exit(exitError)
+				}
+				if !scan.OwnersSupported {
+					fmt.Fprintln(os.Stderr, "--by-owner is not supported on this platform; grouping by extension")
+					byOwner = false
+				}
+			}
+
+			// categoryMap groups the scan by category; --summary-as-tree
+			// keeps extensions and groups them only in the summary
+			var categoryMap, treeCategories map[string]string
+			if summaryTree && chart {
+				fmt.Println("--summary-as-tree cannot be combined with --chart")
+				os.Exit(exitError)
+			}
+			if categories || categoriesFile != "" || summaryTree {
+				if byMIME {
+					fmt.Println("--categories and --summary-as-tree cannot be combined with --by-mime")
+					os.Exit(exitError)
+				}
+				m, err := loadCategories(categoriesFile)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(exitError)
+				}
+				if summaryTree {
+					treeCategories = m
+				} else {
+					categoryMap = m
+				}
+			}
+
+			var allowed map[string]bool
+			if allowlist != "" {
+				a, err := loadAllowlist(allowlist, caseSensitive)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(exitError)
+				}
+				allowed = a
+			}
+
+			var saved map[string]savedExtension
+			if diffFile != "" {
+				s, err := loadSavedScan(diffFile)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(exitError)
+				}
+				saved = s
+			}
+
+			if checksumManifest {
+				if output == "" {
+					fmt.Println("--checksum-manifest requires --output")
+					os.Exit(exitError)
+				}
+				if _, ok := hashAlgorithms[hashAlgorithm]; !ok {
+					fmt.Printf("Invalid --hash %q: must be md5, sha1, sha256 or sha512\n", hashAlgorithm)
+					os.Exit(exitError)
+				}
+			} else if manifestSizes {
+				fmt.Println("--manifest-sizes requires --checksum-manifest")
+				os.Exit(exitError)
+			}
+
+			var minSizeBytes int64
+			if minSize != "" {
+				n, err := parseSize(minSize)
+				if err != nil {
+					fmt.Printf("Invalid --min-size: %v\n", err)
+					os.Exit(exitError)
+				}
+				minSizeBytes = n
+			}
+			if minCount < 0 {
+				fmt.Println("Invalid --min-count: must not be negative")
+				os.Exit(exitError)
+			}
+
+			var customFormat *outputFormat
+			if rowFormat == "" && (headerFormat != "" || footerFormat != "") {
+				fmt.Println("--format-header and --format-footer need --format")
+				os.Exit(exitError)
+			}
+			if rowFormat != "" {
+				f, err := parseOutputFormat(rowFormat, headerFormat, footerFormat)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(exitError)
+				}
+				customFormat = f
+			}
+
+			var maxTotalBytes int64
+			if maxTotal != "" {
+				n, err := parseSize(maxTotal)
+				if err != nil {
+					fmt.Printf("Invalid --max-total: %v\n", err)
+					os.Exit(exitError)
+				}
+				maxTotalBytes = n
+			}
+			var failOverBytes int64
+			if failIfOver != "" {
+				n, err := parseSize(failIfOver)
+				if err != nil {
+					fmt.Printf("Invalid --fail-if-over: %v\n", err)
+					os.Exit(exitError)
+				}
+				failOverBytes = n
+			} else if failExt != "" {
+				fmt.Println("--fail-ext needs --fail-if-over")
+				os.Exit(exitError)
+			}
+
+			roots := paths
+			if glob {
+				roots = nil
+				for _, pattern := range paths {
+					matches, err := expandGlobRoots(pattern)
+					if err != nil {
+						fmt.Println(err)
+						os.Exit(exitError)
+					}
+					roots = append(roots, matches...)
+				}
+			}
+			roots = dedupeRoots(roots)
+
+			if watch {
+				if interval <= 0 {
+					fmt.Println("--interval must be positive")
+					os.Exit(exitError)
+				}
+				if fromStdin || jsonOutput || csvOutput != "" || htmlOutput != "" || markdown || customFormat != nil || quiet {
+					fmt.Println("--watch cannot be combined with --stdin, --json, --csv, --html, --markdown, --format or --quiet")
+					os.Exit(exitError)
+				}
+			}
+			if interactive {
+				if watch || fromStdin || jsonOutput || csvOutput == "-" || markdown || customFormat != nil || quiet {
+					fmt.Println("--interactive cannot be combined with --watch, --stdin, --json, --csv -, --markdown, --format or --quiet")
+					os.Exit(exitError)
+				}
+				if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+					fmt.Println("--interactive needs a terminal")
+					os.Exit(exitError)
+				}
+			}
+
+			// with --stdin or --from-file, the path only resolves relative
+			// entries and is not walked
+			if fromStdin && fromFile != "" {
+				fmt.Println("--stdin cannot be combined with --from-file")
+				os.Exit(exitError)
+			}
+			listed := fromStdin || fromFile != ""
+			walked := roots
+			if listed {
+				if len(roots) != 1 {
+					fmt.Println("--stdin and --from-file take at most one path, to resolve relative entries against")
+					os.Exit(exitError)
+				}
+				walked = nil
+			}
+			var list io.Reader = os.Stdin
+			if fromFile != "" {
+				f, err := os.Open(fromFile)
+				if err != nil {
+					fmt.Printf("Error opening --from-file: %v\n", err)
+					os.Exit(exitError)
+				}
+				defer f.Close()
+				list = f
+			}
+
+			needEngine := false
+			for _, root := range walked {
+				if scan.IsArchiveRoot(root) {
+					if checksumManifest {
+						fmt.Println("--checksum-manifest cannot hash entries inside an archive root")
+						os.Exit(exitError)
+					}
+					if dedupe {
+						fmt.Println("--dedupe cannot hash entries inside an archive root")
+						os.Exit(exitError)
+					}
+					if owner != "" {
+						fmt.Println("--owner cannot filter entries inside an archive root")
+						os.Exit(exitError)
+					}
+					continue
+				}
+				needEngine = true
+				if !noConfirm && !dryRun && !confirmLargeScan(root, confirmThreshold) {
+					fmt.Fprintln(os.Stderr, "Aborted.")
+					os.Exit(exitError)
+				}
+			}
+
+			// auto = fd when installed, otherwise the native walker
+			var fdCmdName string
+			if needEngine && engine != "native" {
+				name, err := scan.FindFd()
+				if err != nil && engine == "fd" {
+					fmt.Println("Failed to find fdfind on your system. Please ensure it has been installed, and is in your PATH, or use --engine native.")
+					os.Exit(exitError)
+				}
+				fdCmdName = name
+			}
+			if needEngine {
+				if fdCmdName != "" {
+					logger.Info("using fd", "path", fdCmdName)
+				} else if engine == "auto" {
+					logger.Info("using the native walker", "reason", "fd not found in PATH")
+				} else {
+					logger.Info("using the native walker")
+				}
+			}
+
+			// Ctrl-C (or SIGTERM) and --timeout cancel the scan and stop fd
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			// with --watch the timeout applies to each scan instead (see runWatch)
+			if timeout > 0 && !watch {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			if maxDepth < 0 {
+				fmt.Println("--max-depth must not be negative")
+				os.Exit(exitError)
+			}
+			filter := scan.Filter{Extensions: extensions, Excludes: excludes, Gitignore: gitignore, RespectIgnore: respectIgnore, GitignoreInReposOnly: gitignoreInRepos, NoHidden: noHidden, MaxDepth: maxDepth, FollowSymlinks: followSymlinks, CaseSensitive: caseSensitive}
+			for _, p := range nameGlobs {
+				if _, err := path.Match(p, ""); err != nil {
+					fmt.Printf("Invalid --name-glob %q: %v\n", p, err)
+					os.Exit(exitError)
+				}
+			}
+			filter.NameGlobs = nameGlobs
+			if owner != "" {
+				uid, err := scan.LookupOwner(owner)
+				if err != nil {
+					fmt.Printf("Invalid --owner: %v\n", err)
+					os.Exit(exitError)
+				}
+				filter.Owner = uid
+			}
+			if extRegex != "" {
+				re, err := regexp.Compile(extRegex)
+				if err != nil {
+					fmt.Printf("Invalid --ext-regex: %v\n", err)
+					os.Exit(exitError)
+				}
+				filter.ExtRegex = re
+			}
+			now := time.Now()
+			if modifiedAfter != "" {
+				t, err := scan.ParseTimeBound(modifiedAfter, now)
+				if err != nil {
+					fmt.Printf("Invalid --modified-after: %v\n", err)
+					os.Exit(exitError)
+				}
+				filter.ModifiedAfter = t
+			}
+			if modifiedBefore != "" {
+				t, err := scan.ParseTimeBound(modifiedBefore, now)
+				if err != nil {
+					fmt.Printf("Invalid --modified-before: %v\n", err)
+					os.Exit(exitError)
+				}
+				filter.ModifiedBefore = t
+			}
+			if !filter.ModifiedAfter.IsZero() && !filter.ModifiedBefore.IsZero() &&
+				!filter.ModifiedAfter.Before(filter.ModifiedBefore) {
+				fmt.Println("--modified-after must be earlier than --modified-before")
+				os.Exit(exitError)
+			}
+
+			if maxExtLen <= 0 {
+				fmt.Println("--max-ext-len must be positive")
+				os.Exit(exitError)
+			}
+			opts := scan.Options{
+				Filter:         filter,
+				ExtensionRules: scan.ExtensionRules{MaxLength: maxExtLen, KeepAll: keepAllExt, SplitUnusual: splitUnusual},
+				FdCommand:      fdCmdName,
+				Jobs:           jobs,
+				QuietErrors:    quietErrors,
+				Spill:          spill,
+				SpillBudget:    spillBudget,
+				SpillLess:      fileLess,
+
+				HardlinksOnce: hardlinksOnce,
+				DiskUsage:     diskUsage,
+			}
+			if byMIME {
+				opts.Classify = scan.ClassifyMIME
+			}
+			if byOwner {
+				opts.Classify = scan.ClassifyOwner
+			}
+			// aliases apply to extensions only, before any --categories grouping
+			var merges map[string]string
+			if !byMIME && !byOwner {
+				m, err := parseMerges(mergeSpecs, !noDefaultMerges, caseSensitive)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(exitError)
+				}
+				merges = m
+			}
+			switch {
+			case len(merges) > 0 && categoryMap != nil:
+				merge, category := mergeOf(merges), categoryOf(categoryMap)
+				opts.Group = func(ext string) string { return category(merge(ext)) }
+			case len(merges) > 0:
+				opts.Group = mergeOf(merges)
+			case categoryMap != nil:
+				opts.Group = categoryOf(categoryMap)
+			}
+			opts.FdWarning = func(line string) {
+				logger.Warn("fd reported a problem", "message", line)
+			}
+			if logger.Enabled(ctx, slog.LevelInfo) && (gitignore || respectIgnore) {
+				for _, root := range walked {
+					if scan.IsArchiveRoot(root) {
+						continue
+					}
+					if _, ok := scan.FindRepoRoot(root); ok {
+						continue
+					}
+					// fd never applies .gitignore outside a repository
+					if fdCmdName == "" && !gitignoreInRepos {
+						logger.Info("path is not inside a git repository; its .gitignore files are applied anyway", "root", root)
+					} else {
+						logger.Info("path is not inside a git repository, so .gitignore files only apply in repositories below it", "root", root)
+					}
+				}
+			}
+			if logger.Enabled(ctx, slog.LevelInfo) && !quietErrors {
+				opts.OnError = func(e scan.ScanError) {
+					logger.Info("skipped unreadable path", "path", e.Path, "error", e.Err)
+				}
+				for _, root := range walked {
+					if fdCmdName != "" && !scan.IsArchiveRoot(root) {
+						logger.Info("fd arguments", "root", root, "args", scan.FdArgs(root, opts.Filter))
+					}
+				}
+			}
+			logger.Debug("filters",
+				"extensions", filter.Extensions,
+				"excludes", filter.Excludes,
+				"max_depth", filter.MaxDepth,
+				"no_hidden", filter.NoHidden,
+				"gitignore", filter.Gitignore,
+				"respect_ignore", filter.RespectIgnore,
+				"gitignore_in_repos_only", filter.GitignoreInReposOnly,
+				"follow_symlinks", filter.FollowSymlinks)
+			if dryRun {
+				if listed {
+					fmt.Println("--dry-run cannot be combined with --stdin or --from-file")
+					os.Exit(exitError)
+				}
+				printDryRun(roots, opts)
+				return
+			}
+			var cacheID string
+			if useCache {
+				if listed || spill || watch {
+					fmt.Println("--cache cannot be combined with --stdin, --from-file, --spill or --watch")
+					os.Exit(exitError)
+				}
+				key, err := cacheKey(roots, opts, byMIME, byOwner, categoryMap, merges)
+				if err != nil {
+					fmt.Printf("Error preparing --cache: %v\n", err)
+					os.Exit(exitError)
+				}
+				cacheID = key
+			}
+			if watch {
+				err := runWatch(ctx, interval, timeout, func(ctx context.Context) (*scan.Stats, error) {
+					stats, err := scan.ScanRoots(ctx, roots, opts)
+					if uerr := stats.Unspill(fileLimit); err == nil {
+						err = uerr
+					}
+					return stats, err
+				}, func(stats *scan.Stats) {
+					if len(stats.Sizes) == 0 {
+						fmt.Println("No files found.")
+						return
+					}
+					sortedExtensions := scan.SortedExtensions(stats.Sizes, stats.Counts, sortName, sortCount, reverseSize)
+					sortedExtensions = filterMinSize(sortedExtensions, stats.Sizes, minSizeBytes)
+					sortedExtensions = filterMinCount(sortedExtensions, stats.Counts, minCount)
+					sortedExtensions = collapseToTop(stats, sortedExtensions, top, otherLabel)
+					totalSize := summaryTotal(stats, sortedExtensions, totalUnfiltered)
+					if detail || folderDetail {
+						printDetails(sortedExtensions, stats, detail, folderDetail, nil, nil, fileLimit, dirLimit, fileLess, dirLess, onlyWithFiles, redactRootFor(roots, absolute), outputWidth(maxWidth))
+						fmt.Println()
+					}
+					if treeCategories != nil {
+						printCategoryTree(groupByCategory(sortedExtensions, stats.Sizes, stats.Counts, treeCategories, reverseSize), stats.Sizes, stats.Counts, totalSize, total, showCount, showPercent, ascii)
+					} else {
+						printSummary(sortedExtensions, stats.Sizes, stats.Counts, totalSize, total, showCount, showPercent, chart, ascii, outputWidth(maxWidth))
+					}
+					if stats.Skipped > 0 {
+						fmt.Printf("%s file(s) could not be read\n", formatCount(int64(stats.Skipped)))
+					}
+				})
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(exitError)
+				}
 				return
 			}
 
-			sortedExtensions := collectSortedExtensions(stats.Sizes, sortName, reverseSize)
+			var cached *scan.Stats
+			if cacheID != "" {
+				cached = loadCache(cacheID)
+				if cached != nil {
+					logger.Debug("reusing the cached scan", "key", cacheID)
+				} else {
+					logger.Debug("no usable cached scan", "key", cacheID)
+				}
+			}
+
+			var progress *progressReporter
+			if cached == nil && !noProgress && isTerminal(os.Stderr) {
+				progress = newProgressReporter(os.Stderr)
+				opts.Progress = progress.update
+			}
 
-			// show the detailed per-extension block only when -f or -d is used
-			// if the user just passes -e, we skip this and only show the summary
-			if detail || folderDetail {
-				printDetails(sortedExtensions, stats, detail, folderDetail, limit, reverseSize)
-				fmt.Println()
+			var stats *scan.Stats
+			var scanErr error
+			scanStarted := time.Now()
+			if cached != nil {
+				stats = cached
+			} else if listed {
+				stats, scanErr = scan.ScanList(ctx, list, roots[0], opts)
+			} else {
+				stats, scanErr = scan.ScanRoots(ctx, roots, opts)
 			}
+			scanTook := time.Since(scanStarted)
 
-			// final summary
-			printSummary(sortedExtensions, stats.Sizes, total)
+			partial := false
+			switch {
+			case errors.Is(scanErr, context.DeadlineExceeded):
+				// keep what was scanned so far and report it
+				partial = true
+				scanErr = nil
+			case errors.Is(scanErr, context.Canceled):
+				stats.Unspill(0)
+				if progress != nil {
+					progress.finish()
+				}
+				fmt.Fprintln(os.Stderr, "Interrupted.")
+				os.Exit(exitInterrupted)
+			}
+
+			if progress != nil {
+				progress.finish()
+			}
+			var files int
+			for _, n := range stats.Counts {
+				files += n
+			}
+			logger.Info("scan finished", "files", files, "skipped", stats.Skipped, "cached", cached != nil, "partial", partial, "duration", scanTook)
+			if timing {
+				if cached != nil {
+					fmt.Fprintf(os.Stderr, "Loaded %s files from the cache in %s\n", formatCount(int64(files)), scanTook.Round(time.Millisecond))
+				} else {
+					fmt.Fprintf(os.Stderr, "Scanned %s files in %s\n", formatCount(int64(files)), scanTook.Round(time.Millisecond))
+				}
+			}
+			// only complete scans are cached
+			if cacheID != "" && cached == nil && scanErr == nil && !partial {
+				if err := saveCache(cacheID, roots, stats); err != nil && !quietErrors {
+					logger.Warn("could not save the scan to the cache", "error", err)
+				}
+			}
+
+			var bands []ageBand
+			if scanErr == nil && ageBands {
+				bands, scanErr = computeAgeBands(stats, edges, time.Now())
+			}
+			var buckets []sizeBucket
+			if scanErr == nil && histogram {
+				buckets, scanErr = computeHistogram(stats, sizeEdges)
+			}
+			var violations []allowlistViolation
+			if scanErr == nil && allowed != nil {
+				violations, scanErr = findViolations(stats, allowed)
+			}
+			redact := redactRootFor(roots, absolute)
+			if scanErr == nil && checksumManifest {
+				scanErr = writeManifest(output, stats, hashAlgorithm, redact, manifestSizes)
+			}
+			var biggestList []scan.FileDetail
+			if scanErr == nil && biggest > 0 {
+				biggestList, scanErr = biggestFiles(stats, biggest)
+			}
+			var hotList []scan.FileDetail
+			if hot > 0 {
+				hotList = hotFolders(stats, hot)
+			}
+			var timeRanges map[string]timeRange
+			if scanErr == nil && showTimes {
+				timeRanges, scanErr = collectTimes(stats)
+			}
+			var sizes map[string][]int64
+			if scanErr == nil && showStats {
+				sizes, scanErr = collectSizes(stats)
+			}
+			var empty map[string][]string
+			if scanErr == nil && showEmpty {
+				empty, scanErr = findEmptyFiles(stats)
+			}
+			var duplicates dupReport
+			if scanErr == nil && dedupe {
+				duplicates, scanErr = findDuplicates(stats, jobs)
+			}
+			// spilled records are only needed until the listed files are selected
+			if err := stats.Unspill(fileLimit); scanErr == nil {
+				scanErr = err
+			}
+			if scanErr != nil {
+				fmt.Println(scanErr)
+				os.Exit(exitError)
+			}
+
+			// collect all extensions we saw; nothing found exits with 2 (see
+			// the end of Run), after JSON/CSV output has still been written
+			if len(stats.Sizes) == 0 && !jsonOutput && csvOutput == "" {
+				fmt.Fprintln(os.Stderr, "No files found.")
+				if partial {
+					fmt.Fprintf(os.Stderr, "Scan timed out after %s; results are partial\n", timeout)
+					os.Exit(exitPartial)
+				}
+				os.Exit(exitNoFiles)
+			}
+
+			// checked before --top can fold the named extensions into "other"
+			var oversized []string
+			if failIfOver != "" {
+				oversized = overLimit(stats.Sizes, failExtensions(failExt, merges), failOverBytes)
+			}
+
+			sortedExtensions := scan.SortedExtensions(stats.Sizes, stats.Counts, sortName, sortCount, reverseSize)
+			sortedExtensions = filterMinSize(sortedExtensions, stats.Sizes, minSizeBytes)
+			sortedExtensions = filterMinCount(sortedExtensions, stats.Counts, minCount)
+			sortedExtensions = collapseToTop(stats, sortedExtensions, top, otherLabel)
+			if showTimes {
+				foldTimes(timeRanges, stats, otherLabel)
+			}
+			var sizeSummary map[string]sizeStats
+			if showStats {
+				foldSizes(sizes, stats, otherLabel)
+				sizeSummary = summarizeSizes(sizes)
+			}
+
+			totalSize := summaryTotal(stats, sortedExtensions, totalUnfiltered)
+
+			var deltas []extDelta
+			if saved != nil {
+				deltas = diffScans(saved, sortedExtensions, stats.Sizes, stats.Counts)
+			}
+
+			if csvOutput != "" {
+				if err := writeCSV(csvOutput, sortedExtensions, stats, totalSize, total); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(exitError)
+				}
+			}
+			if htmlOutput != "" {
+				if err := writeHTML(htmlOutput, roots, sortedExtensions, stats, totalSize, time.Now()); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(exitError)
+				}
+			}
+
+			switch {
+			case csvOutput == "-":
+				// CSV on stdout replaces the text report
+			case interactive:
+				if err := runBrowser(stats, sortedExtensions, totalSize, redact); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(exitError)
+				}
+			case quiet:
+				// just the total; anything else worth knowing goes to stderr.
+				// --bytes prints plain digits here, so scripts can read them
+				if rawBytes {
+					fmt.Println(totalSize)
+				} else {
+					fmt.Println(formatSize(totalSize))
+				}
+				if stats.Skipped > 0 {
+					fmt.Fprintf(os.Stderr, "%s file(s) could not be read\n", formatCount(int64(stats.Skipped)))
+				}
+			case markdown:
+				printMarkdown(sortedExtensions, stats.Sizes, stats.Counts, totalSize, total)
+				if stats.Skipped > 0 {
+					fmt.Fprintf(os.Stderr, "%s file(s) could not be read\n", formatCount(int64(stats.Skipped)))
+				}
+			case customFormat != nil:
+				if err := customFormat.print(os.Stdout, sortedExtensions, stats.Sizes, stats.Counts, totalSize); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(exitError)
+				}
+				if stats.Skipped > 0 {
+					fmt.Fprintf(os.Stderr, "%s file(s) could not be read\n", formatCount(int64(stats.Skipped)))
+				}
+			case jsonOutput:
+				report := buildJSONReport(sortedExtensions, stats, totalSize, fileLimit, dirLimit, fileLess, dirLess, redact)
+				report.AgeBands = bands
+				report.Histogram = buckets
+				if showTree {
+					report.Tree = buildTree(stats, roots, limit, maxDepth)
+				}
+				for _, f := range hotList {
+					report.HotFolders = append(report.HotFolders, jsonFolder{
+						Path:      displayPath(f.Path, redact),
+						Size:      f.Size,
+						Formatted: formatSize(f.Size),
+					})
+				}
+				report.Diff = deltas
+				for i, e := range report.Extensions {
+					if s, ok := sizeSummary[e.Extension]; ok {
+						report.Extensions[i].SizeStats = &s
+					}
+					if r, ok := timeRanges[e.Extension]; ok {
+						report.Extensions[i].Times = &r
+					}
+				}
+				if cooccurrence {
+					report.Cooccurrence = computeCooccurrence(stats)
+				}
+				for _, f := range biggestList {
+					report.Biggest = append(report.Biggest, jsonFile{
+						Path:      displayPath(f.Path, redact),
+						Size:      f.Size,
+						Formatted: formatSize(f.Size),
+						ModTime:   f.ModTime,
+					})
+				}
+				for _, v := range violations {
+					report.Violations = append(report.Violations, jsonViolation{
+						Extension: v.Ext,
+						Path:      displayPath(v.File.Path, redact),
+						Size:      v.File.Size,
+					})
+				}
+				if showEmpty {
+					report.EmptyFiles = make(map[string][]string, len(empty))
+					for ext, list := range empty {
+						for _, p := range list {
+							report.EmptyFiles[ext] = append(report.EmptyFiles[ext], displayPath(p, redact))
+						}
+					}
+				}
+				if dedupe {
+					for i := range duplicates.Groups {
+						for j, p := range duplicates.Groups[i].Paths {
+							duplicates.Groups[i].Paths[j] = displayPath(p, redact)
+						}
+					}
+					report.Duplicates = &duplicates
+				}
+				if err := writeJSON(report); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(exitError)
+				}
+			default:
+				// show the detailed per-extension block only when -f or -d is used
+				// if the user just passes -e, we skip this and only show the summary
+				if detail || folderDetail || sizeSummary != nil || timeRanges != nil {
+					printDetails(sortedExtensions, stats, detail, folderDetail, sizeSummary, timeRanges, fileLimit, dirLimit, fileLess, dirLess, onlyWithFiles, redact, outputWidth(maxWidth))
+					fmt.Println()
+				}
+
+				// final summary; --biggest on its own replaces it
+				if biggest > 0 {
+					printBiggest(biggestList, redact)
+				}
+				if biggest == 0 || detail || folderDetail || sizeSummary != nil || timeRanges != nil {
+					if biggest > 0 {
+						fmt.Println()
+					}
+					if treeCategories != nil {
+						printCategoryTree(groupByCategory(sortedExtensions, stats.Sizes, stats.Counts, treeCategories, reverseSize), stats.Sizes, stats.Counts, totalSize, total, showCount, showPercent, ascii)
+					} else {
+						printSummary(sortedExtensions, stats.Sizes, stats.Counts, totalSize, total, showCount, showPercent, chart, ascii, outputWidth(maxWidth))
+					}
+				}
+
+				if ageBands {
+					fmt.Println()
+					printAgeBands(bands)
+				}
+
+				if hot > 0 {
+					fmt.Println()
+					printHotFolders(hotList, redact)
+				}
+
+				if showTree {
+					fmt.Println()
+					printTree(buildTree(stats, roots, limit, maxDepth))
+				}
+
+				if histogram {
+					fmt.Println()
+					printHistogram(buckets, ascii, outputWidth(maxWidth))
+				}
+
+				if cooccurrence {
+					fmt.Println()
+					printCooccurrence(computeCooccurrence(stats), limit)
+				}
+
+				if len(violations) > 0 {
+					fmt.Println()
+					printViolations(violations, redact)
+				}
+
+				if showEmpty {
+					fmt.Println()
+					printEmptyFiles(empty, redact)
+				}
+
+				if dedupe {
+					fmt.Println()
+					printDuplicates(duplicates, limit, redact)
+				}
+
+				if saved != nil {
+					fmt.Println()
+					printDiff(deltas, diffFile)
+				}
+
+				if showErrors && !quietErrors && len(stats.Errors) > 0 {
+					fmt.Println()
+					printErrors(stats.Errors, redact)
+				}
+				if stats.Skipped > 0 {
+					note := "; use --show-errors for details"
+					if showErrors || quietErrors {
+						note = ""
+					}
+					fmt.Printf("%s file(s) could not be read%s\n", formatCount(int64(stats.Skipped)), note)
+				}
+			}
+
+			if partial {
+				fmt.Fprintf(os.Stderr, "Scan timed out after %s; results are partial\n", timeout)
+			}
+
+			failed := len(violations) > 0 || len(oversized) > 0
+			for _, msg := range oversized {
+				fmt.Fprintln(os.Stderr, msg)
+			}
+			if maxTotal != "" {
+				var totalSize int64
+				for _, size := range stats.Sizes {
+					totalSize += size
+				}
+				if totalSize > maxTotalBytes {
+					fmt.Fprintf(os.Stderr, "Total %s exceeds --max-total %s by %s\n",
+						formatSize(totalSize), formatSize(maxTotalBytes), formatSize(totalSize-maxTotalBytes))
+					failed = true
+				}
+			}
+			if failed {
+				os.Exit(exitError)
+			}
+			if partial {
+				os.Exit(exitPartial)
+			}
+			if len(stats.Sizes) == 0 {
+				os.Exit(exitNoFiles)
+			}
 		},
 	}
 
-	rootCmd.Flags().StringVarP(&path, "path", "p", "", "Path to search (default: current directory)")
+	rootCmd.Flags().StringVar(&configFile, "config", "", "Read default flag values from this YAML file (default: ./.extdust.yaml or ~/.config/extdust/config.yaml)")
+	rootCmd.Flags().StringArrayVarP(&paths, "path", "p", nil, "Path to search, or a tar/zip archive to inspect (repeatable, or pass paths as arguments; default: current directory)")
+	rootCmd.Flags().BoolVar(&fromStdin, "stdin", false, "Read the files to count from stdin, one path per line, instead of scanning (relative paths resolve against the path)")
+	rootCmd.Flags().StringVar(&fromFile, "from-file", "", "Like --stdin, but read the list of files from this file")
+	rootCmd.Flags().StringVar(&engine, "engine", "auto", "Scan engine: fd, native (built-in walker), or auto (fd if installed, else native)")
+	rootCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Browse the results in the terminal: open an extension to see its files and folders, re-sort and switch units (q quits)")
+	rootCmd.Flags().BoolVar(&watch, "watch", false, "Rescan every --interval and redraw the summary in place until Ctrl-C")
+	rootCmd.Flags().DurationVar(&interval, "interval", 5*time.Second, "Time between scans with --watch (e.g. 30s, 5m)")
+	rootCmd.Flags().DurationVar(&timeout, "timeout", 0, "Stop scanning after this long (e.g. 30s) and report partial results")
+	rootCmd.Flags().IntVarP(&jobs, "jobs", "J", runtime.NumCPU(), "Number of files to stat concurrently with the fd engine, or to sniff at once with --by-mime")
+	rootCmd.Flags().BoolVar(&glob, "glob", false, "Treat each --path as a glob pattern and scan every matching directory")
 	rootCmd.Flags().StringVarP(&extensions, "ext", "e", "", "Comma-separated file extensions to search for")
+	rootCmd.Flags().StringArrayVar(&nameGlobs, "name-glob", nil, "Only count files whose name matches this glob, e.g. 'Dockerfile*' (repeatable, OR-combined)")
+	rootCmd.Flags().StringVar(&extRegex, "ext-regex", "", "Only count files whose extension (lower-case, without the dot) matches this regular expression")
+
+	rootCmd.Flags().IntVar(&maxExtLen, "max-ext-len", scan.DefaultMaxExtLength, "Longest suffix (in bytes) counted as an extension; longer ones count as \"no extension\"")
+	rootCmd.Flags().BoolVar(&keepAllExt, "keep-all-ext", false, "Count every suffix as an extension, however long or unusual (disables the \"no extension\" heuristic)")
+	rootCmd.Flags().BoolVar(&splitUnusual, "split-unusual", false, "Count suffixes that are not accepted as extensions under UNUSUAL instead of \"no extension\"")
+	rootCmd.Flags().BoolVar(&caseSensitive, "case-sensitive", false, "Keep extensions as found (JPG and jpg are counted separately) and match -e exactly")
+
+	rootCmd.Flags().BoolVar(&followSymlinks, "follow-symlinks", false, "Count the targets of symlinks and descend into symlinked directories (loops are detected)")
+	rootCmd.Flags().BoolVar(&hardlinksOnce, "count-hardlinks-once", false, "Count a file with several hardlinks once, like du (no effect where inodes are unavailable)")
+	rootCmd.Flags().BoolVar(&diskUsage, "disk-usage", false, "Report allocated disk space (blocks) instead of apparent file sizes, like du")
+	rootCmd.Flags().IntVar(&maxDepth, "max-depth", 0, "Only descend this many levels below the root (1 = files directly under it; default: unlimited)")
+	rootCmd.Flags().StringArrayVarP(&excludes, "exclude", "x", nil, "Skip paths matching this glob, relative to the root (repeatable; ** matches any depth)")
+
+	rootCmd.Flags().StringVar(&modifiedAfter, "modified-after", "", "Only count files modified at or after this time (RFC3339, YYYY-MM-DD, or an age like 90d)")
+	rootCmd.Flags().StringVar(&modifiedBefore, "modified-before", "", "Only count files modified before this time (RFC3339, YYYY-MM-DD, or an age like 30d)")
+	rootCmd.Flags().BoolVar(&noHidden, "no-hidden", false, "Skip hidden files and directories (names starting with a dot)")
+	rootCmd.Flags().BoolVar(&gitignore, "gitignore", false, "Don't count files ignored by .gitignore")
+	rootCmd.Flags().BoolVar(&gitignoreInRepos, "follow-gitignore-only-in-repos", false, "With --gitignore or --respect-ignore, apply .gitignore files only inside git repositories, as fd does")
+	rootCmd.Flags().BoolVar(&respectIgnore, "respect-ignore", false, "Don't count files ignored by .gitignore, .ignore or .fdignore (lets fd apply its ignore files; off = fd -I)")
+
+	rootCmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Print the full results as a single JSON document instead of text")
+	rootCmd.Flags().BoolVar(&useCache, "cache", false, "Reuse the last scan of the same paths and filters unless a scanned folder was modified since")
+	rootCmd.Flags().CountVarP(&verbosity, "verbose", "v", "Log what the scan does to stderr; repeat (-vv) for more detail")
+	rootCmd.Flags().StringVar(&logFormat, "log-format", "text", "Format of the log messages on stderr: text or json")
+	rootCmd.Flags().StringVar(&logLevel, "log-level", "", "Lowest level of log messages shown: debug, info, warn or error (default: warn, lowered by -v)")
+	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the resolved paths and the fd command for each, then exit without scanning")
+	rootCmd.Flags().BoolVar(&timing, "timing", false, "Print how long the scan took, and how many files it counted, to stderr")
+	rootCmd.Flags().BoolVar(&markdown, "markdown", false, "Print the summary as a Markdown table (Extension | Size | Files | Percent) instead of text")
+	rootCmd.Flags().StringVar(&rowFormat, "format", "", "Print the summary with a Go template run per extension (fields .Ext, .Size, .HumanSize, .Count, .Percent) instead of text")
+	rootCmd.Flags().StringVar(&headerFormat, "format-header", "", "Template printed once before the --format lines (fields .Size, .HumanSize, .Count, .Extensions)")
+	rootCmd.Flags().StringVar(&footerFormat, "format-footer", "", "Template printed once after the --format lines (same fields as --format-header)")
+
+	rootCmd.Flags().StringVar(&csvOutput, "csv", "", "Write the summary as CSV to stdout, or to a file with --csv=FILE")
+	rootCmd.Flags().Lookup("csv").NoOptDefVal = "-"
+	rootCmd.Flags().StringVar(&diffFile, "diff", "", "Compare with a scan saved earlier with --json and show the change per extension")
+	rootCmd.Flags().StringVar(&htmlOutput, "html", "", "Also write the summary as a standalone HTML page with a sortable table to this file")
+
+	rootCmd.Flags().BoolVar(&categories, "categories", false, "Group extensions into categories (Images, Video, Audio, Documents, Code, Archives, Other)")
+	rootCmd.Flags().BoolVar(&summaryTree, "summary-as-tree", false, "Print the summary as a tree: each category (see --categories) with its subtotal and its extensions beneath")
+	rootCmd.Flags().StringVar(&categoriesFile, "categories-file", "", "JSON file of {\"Category\": [\"ext\", ...]} overriding or extending the default categories (implies --categories)")
+	rootCmd.Flags().StringVar(&owner, "owner", "", "Only count files owned by this user (name or numeric uid; Unix only)")
+	rootCmd.Flags().BoolVar(&byOwner, "by-owner", false, "Group by the user owning each file instead of extension (Unix only)")
+	rootCmd.Flags().BoolVar(&byMIME, "by-mime", false, "Group by sniffed MIME type instead of extension (reads the first 512 bytes of every file)")
 
 	rootCmd.Flags().BoolVarP(&detail, "files", "f", false, "Show file details per extension")
 	rootCmd.Flags().BoolVarP(&folderDetail, "dirs", "d", false, "Show folder details per extension")
 
+	rootCmd.Flags().BoolVar(&absolute, "absolute", false, "Show full file and folder paths instead of paths relative to the search root (./...)")
+	rootCmd.Flags().BoolVar(&redactRoot, "redact-root", false, "Show paths relative to the search root (now the default)")
+	rootCmd.Flags().MarkDeprecated("redact-root", "paths are relative by default; use --absolute for full paths")
+	rootCmd.Flags().StringVar(&colorMode, "color", "auto", "Color the text report: auto (terminal only, unless NO_COLOR is set), always or never")
+	rootCmd.Flags().IntVar(&maxWidth, "max-width", 0, "Shorten long paths in -f/-d to fit this many columns (default: terminal width; no limit when piped)")
+	rootCmd.Flags().BoolVar(&sortTime, "sort-time", false, "Order files in the --files view by modification time, newest first (-s for oldest); same as --detail-sort mtime")
+	rootCmd.Flags().StringVar(&detailSort, "detail-sort", "size", "Order files in the --files view by size or mtime (newest first, -s for oldest)")
+	rootCmd.Flags().BoolVar(&onlyWithFiles, "only-ext-with-files", false, "Leave extensions without a file list out of the -f/-d details instead of labelling them (they stay in the summary)")
+	rootCmd.Flags().StringVar(&fileSort, "file-sort", "", "Order files in the file details (-f): size-asc, size-desc or name (default: --detail-sort and -s)")
+	rootCmd.Flags().StringVar(&dirSort, "dir-sort", "", "Order folders in the folder details (-d): size-asc, size-desc or name (default: by size, following -s)")
+
+	rootCmd.Flags().StringArrayVar(&mergeSpecs, "merge", nil, "Count an extension under another, e.g. --merge yml=yaml or --merge jpeg,jpe=jpg (repeatable)")
+	rootCmd.Flags().BoolVar(&noDefaultMerges, "no-default-merges", false, "Don't apply the built-in merges (yml=yaml, htm=html, jpeg=jpg, tif=tiff, mpeg=mpg)")
+	rootCmd.Flags().IntVar(&hot, "hot-folders", 0, "Also show the N folders with the most bytes across all extensions")
+	rootCmd.Flags().IntVar(&biggest, "biggest", 0, "Show the N largest files across all extensions (replaces the summary unless -f/-d is given)")
+
 	rootCmd.Flags().IntVarP(&limit, "limit", "l", 100, "Limit the number of results displayed")
+	rootCmd.Flags().IntVar(&fileLimit, "file-limit", 0, "Number of files listed per extension with -f (default: --limit)")
+	rootCmd.Flags().IntVar(&dirLimit, "dir-limit", 0, "Number of folders listed per extension with -d (default: --limit)")
 
 	rootCmd.Flags().BoolVarP(&reverseSize, "size", "s", false, "Sort by size, smallest first (default: largest first)")
 	rootCmd.Flags().BoolVarP(&sortName, "name", "n", false, "Sort summary by extension name")
+	rootCmd.Flags().BoolVar(&sortCount, "sort-count", false, "Sort by number of files, most first (-s for fewest first)")
+
+	rootCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Print only the total size (with --bytes, just the integer)")
+	rootCmd.Flags().VarP(&totalFlag, "total", "t", "Show the total size: of the extensions shown (filtered, the default with -t) or of every extension found (all); also what --percent is relative to")
+	rootCmd.Flags().Lookup("total").NoOptDefVal = "filtered"
+	rootCmd.Flags().BoolVar(&totalUnfiltered, "total-unfiltered", false, "Make --total and --percent include extensions hidden by filters such as --min-size")
+	rootCmd.Flags().MarkDeprecated("total-unfiltered", "use --total=all instead")
+	rootCmd.Flags().IntVar(&top, "top", 0, "Keep only the N largest extensions and group the rest into one bucket")
+	rootCmd.Flags().StringVar(&otherLabel, "other-label", "other", "Name of the bucket used by --top")
+	rootCmd.Flags().StringVar(&minSize, "min-size", "", "Hide extensions whose total size is below this size (e.g. 10MB, 500KB)")
+	rootCmd.Flags().IntVar(&minCount, "min-count", 0, "Hide extensions with fewer than this many files")
+	rootCmd.Flags().BoolVarP(&showCount, "count", "c", false, "Show the number of files per extension in the summary")
+	rootCmd.Flags().BoolVar(&showPercent, "percent", false, "Show each extension's share of the total size in the summary")
+	rootCmd.Flags().BoolVar(&chart, "chart", false, "Draw a bar next to each extension in the summary, scaled to the largest (or to the total with --percent)")
+	rootCmd.Flags().BoolVar(&ascii, "ascii", false, "Draw --chart bars with '#' and --summary-as-tree branches with '|--' instead of Unicode characters")
+	rootCmd.Flags().BoolVar(&rawBytes, "bytes", false, "Print sizes as exact byte counts instead of human-readable units")
+	rootCmd.Flags().StringVar(&locale, "locale", "", "Thousands separator style for counts and --bytes sizes, e.g. en (12,340), de (12.340), fr (12 340), de_CH (12'340) or none (default: from LC_ALL/LC_NUMERIC/LANG, else en)")
+	rootCmd.Flags().BoolVar(&siUnits, "si", false, "Use decimal units (1 KB = 1000 bytes) instead of binary units (1 KiB = 1024 bytes)")
+	rootCmd.Flags().BoolVar(&alignSizes, "align-sizes", false, "Pad sizes so decimal points line up vertically")
+
+	rootCmd.Flags().BoolVar(&ageBands, "age-bands", false, "Also show total size and file count grouped by last modification age")
+	rootCmd.Flags().StringVar(&ageBandEdges, "age-band-edges", defaultAgeBands, "Comma-separated age band edges for --age-bands (units: h, d, w, y)")
+	rootCmd.Flags().BoolVar(&showTree, "tree", false, "Also show a directory tree with the size below each folder (top --limit subfolders, --max-depth levels)")
+	rootCmd.Flags().BoolVar(&histogram, "histogram", false, "Also show how many files fall into each size range, as a bar chart")
+	rootCmd.Flags().StringVar(&histogramEdges, "histogram-edges", defaultHistogramEdges, "Comma-separated size edges for --histogram (e.g. 4KiB,1MiB,1GiB)")
+
+	rootCmd.Flags().BoolVar(&cooccurrence, "cooccurrence", false, "Also show which extensions most often share a directory (top --limit pairs)")
+	rootCmd.Flags().BoolVar(&showTimes, "times", false, "Show when the newest and oldest file of each extension was modified (e.g. \"3 days ago\")")
+	rootCmd.Flags().BoolVar(&showStats, "stats", false, "Show min, median, mean and max file size under each extension")
+	rootCmd.Flags().BoolVar(&showEmpty, "empty", false, "Also list zero-byte files, grouped by extension")
+	rootCmd.Flags().BoolVar(&dedupe, "dedupe", false, "Also find files with identical content and show the space they waste (top --limit groups)")
+
+	rootCmd.Flags().BoolVar(&spill, "spill", false, "Keep per-file details in temporary files instead of memory (for --files on huge trees)")
+	rootCmd.Flags().IntVar(&spillBudget, "spill-budget", 100000, "Number of file records held in memory before spilling a sorted run to disk")
+
+	rootCmd.Flags().BoolVar(&checksumManifest, "checksum-manifest", false, "Write a sha256sum-style manifest of every matched file to --output; paths are relative to the root (check it from there) unless --absolute")
+	rootCmd.Flags().BoolVar(&manifestSizes, "manifest-sizes", false, "Add each file's size in bytes as a column between hash and path in --checksum-manifest (no longer readable by sha256sum -c)")
+	rootCmd.Flags().StringVar(&output, "output", "", "File to write the checksum manifest to")
+	rootCmd.Flags().StringVar(&hashAlgorithm, "hash", "sha256", "Hash algorithm for --checksum-manifest: md5, sha1, sha256 or sha512")
+
+	rootCmd.Flags().BoolVar(&noConfirm, "no-confirm", false, "Don't ask for confirmation before scanning a very large root")
+	rootCmd.Flags().IntVar(&confirmThreshold, "confirm-threshold", 5000, "Top-level entry count above which a root is considered very large")
+	rootCmd.Flags().BoolVar(&noProgress, "no-progress", false, "Don't show the live progress line on stderr while scanning")
+	rootCmd.Flags().BoolVar(&showErrors, "show-errors", false, "List every file or directory that could not be read after the report")
+	rootCmd.Flags().BoolVar(&quietErrors, "quiet-errors", false, "Suppress fd's error output and the per-file error lines (and --show-errors); the count of unreadable files is still shown")
+	rootCmd.Flags().StringVar(&allowlist, "allowlist", "", "File listing permitted extensions; report other files and exit non-zero")
+	rootCmd.Flags().StringVar(&failIfOver, "fail-if-over", "", "Exit non-zero if the total, or each --fail-ext extension, exceeds this size (e.g. 5MB)")
+	rootCmd.Flags().StringVar(&failExt, "fail-ext", "", "Comma-separated extensions that --fail-if-over checks one by one instead of the total")
+	rootCmd.Flags().StringVar(&maxTotal, "max-total", "", "Exit non-zero if the total size of matched files exceeds this size (e.g. 2GB)")
 
-	rootCmd.Flags().BoolVarP(&total, "total", "t", false, "Show total size of all extensions combined")
+	rootCmd.AddCommand(newDoctorCmd())
 
 	rootCmd.SilenceUsage = true
 	rootCmd.SilenceErrors = false
 
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitError)
 	}
 }
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// captureStdout returns what fn prints to os.Stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan []byte)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- out
+	}()
+	fn()
+	w.Close()
+	return string(<-done)
+}
+
+// testStats returns stats with two files per extension, recorded smallest first
+func testStats() *scan.Stats {
+	stats := scan.NewStats()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	stats.Sizes = map[string]int64{"go": 30, "md": 5}
+	stats.Counts = map[string]int{"go": 2, "md": 2}
+	stats.Files = map[string][]scan.FileDetail{
+		"go": {{Path: "/r/a.go", Size: 10, ModTime: now}, {Path: "/r/sub/b.go", Size: 20, ModTime: now.Add(-time.Hour)}},
+		"md": {{Path: "/r/a.md", Size: 1, ModTime: now.Add(-2 * time.Hour)}, {Path: "/r/b.md", Size: 4, ModTime: now}},
+	}
+	stats.Folders = map[string]map[string]int64{
+		"go": {"/r": 10, "/r/sub": 20},
+		"md": {"/r": 5},
+	}
+	return stats
+}
+
+func TestPrintDetailsLeavesStatsUnchanged(t *testing.T) {
+	stats := testStats()
+	want := testStats()
+	less := scan.FileLess("size", false)
+
+	captureStdout(t, func() {
+		printDetails([]string{"go", "md"}, stats, true, true, nil, nil, 10, 10, less, less, false, "", 0)
+	})
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("printDetails changed stats:\n got %+v\nwant %+v", stats, want)
+	}
+}
+
+func TestPrintDetailsSkipsEmptyFolders(t *testing.T) {
+	stats := testStats()
+	delete(stats.Folders, "md")
+	less := scan.FileLess("size", false)
+
+	out := captureStdout(t, func() {
+		printDetails([]string{"md"}, stats, false, true, nil, nil, 10, 10, less, less, false, "", 0)
+	})
+	if strings.Contains(out, "Folders:") {
+		t.Errorf("output has a Folders header for an extension without folders:\n%s", out)
+	}
+
+	out = captureStdout(t, func() {
+		printDetails([]string{"go"}, stats, false, true, nil, nil, 10, 10, less, less, false, "", 0)
+	})
+	if !strings.Contains(out, "Folders:") || !strings.Contains(out, "/r/sub (20 bytes)") {
+		t.Errorf("output is missing the folders of go:\n%s", out)
+	}
+}
+
+func TestPrintDetailsWithoutFileList(t *testing.T) {
+	// md keeps its size in the summary, but its files were dropped
+	stats := testStats()
+	delete(stats.Files, "md")
+	less := scan.FileLess("size", false)
+
+	out := captureStdout(t, func() {
+		printDetails([]string{"go", "md"}, stats, true, false, nil, nil, 10, 10, less, less, false, "", 0)
+	})
+	if !strings.Contains(out, "MD: 5 bytes (no files listed)") {
+		t.Errorf("md is not labelled as having no file list:\n%s", out)
+	}
+	if strings.Contains(out, "No files found.") {
+		t.Errorf("md is reported as having no files:\n%s", out)
+	}
+
+	out = captureStdout(t, func() {
+		printDetails([]string{"go", "md"}, stats, true, false, nil, nil, 10, 10, less, less, true, "", 0)
+	})
+	if strings.Contains(out, "MD") {
+		t.Errorf("md is shown with --only-ext-with-files:\n%s", out)
+	}
+	if !strings.HasSuffix(out, "└── /r/a.go (10 bytes)\n") {
+		t.Errorf("go should be the last block, with no separator after it:\n%s", out)
+	}
+}
+
+func TestSortTime(t *testing.T) {
+	tests := []struct {
+		detailSort string
+		set        bool
+		sortTime   bool
+		want       string
+		wantErr    bool
+	}{
+		{"size", false, false, "size", false},
+		{"size", false, true, "mtime", false},
+		{"mtime", true, true, "mtime", false},
+		{"size", true, true, "", true},
+		{"name", true, false, "", true},
+	}
+	for _, tt := range tests {
+		got, err := resolveDetailSort(tt.detailSort, tt.set, tt.sortTime)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("resolveDetailSort(%q, %v, %v) = %q, %v; want %q, error %v", tt.detailSort, tt.set, tt.sortTime, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	// --sort-time lists files exactly as --detail-sort mtime, in both directions
+	stats := testStats()
+	for _, reverse := range []bool{false, true} {
+		order, err := resolveDetailSort("size", false, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		render := func(detailSort string) string {
+			less := sectionLess("", detailSort, reverse)
+			return captureStdout(t, func() {
+				printDetails([]string{"go", "md"}, stats, true, false, nil, nil, 10, 10, less, less, false, "", 0)
+			})
+		}
+		got, want := render(order), render("mtime")
+		if got != want {
+			t.Errorf("reverse=%v: --sort-time output\n%s\ndiffers from --detail-sort mtime\n%s", reverse, got, want)
+		}
+		// newest first, or oldest first with -s
+		first, second := "/r/a.go", "/r/sub/b.go"
+		if reverse {
+			first, second = second, first
+		}
+		if strings.Index(got, first) > strings.Index(got, second) {
+			t.Errorf("reverse=%v: %s should be listed before %s:\n%s", reverse, first, second, got)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		si      bool
+		want    int64
+		wantErr bool
+	}{
+		{in: "1024", want: 1024},
+		{in: "0", want: 0},
+		{in: " 42 ", want: 42},
+		{in: "10B", want: 10},
+		{in: "500K", want: 500 << 10},
+		{in: "500k", want: 500 << 10},
+		{in: "500KB", want: 500 << 10},
+		{in: "500kb", want: 500 << 10},
+		{in: "500KiB", want: 500 << 10},
+		{in: "10MiB", want: 10 << 20},
+		{in: "10 mib", want: 10 << 20},
+		{in: "1.5GB", want: 3 << 29},
+		{in: "1.5g", want: 3 << 29},
+		{in: "2T", want: 2 << 40},
+		{in: ".5K", want: 512},
+		{in: "1.5", want: 1},
+
+		// --si makes K, KB and the like decimal; KiB stays binary
+		{in: "500K", si: true, want: 500_000},
+		{in: "500KB", si: true, want: 500_000},
+		{in: "1.5GB", si: true, want: 1_500_000_000},
+		{in: "500KiB", si: true, want: 500 << 10},
+
+		{in: "", wantErr: true},
+		{in: "   ", wantErr: true},
+		{in: "MB", wantErr: true},
+		{in: "-1K", wantErr: true},
+		{in: "1.2.3K", wantErr: true},
+		{in: "10X", wantErr: true},
+		{in: "10KBB", wantErr: true},
+		{in: "10 bytes", wantErr: true},
+		{in: "100000000000T", wantErr: true},
+	}
+	defer func(si bool) { siUnits = si }(siUnits)
+	for _, tt := range tests {
+		siUnits = tt.si
+		got, err := parseSize(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseSize(%q) = %d, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseSize(%q) with si=%v = %d, %v; want %d", tt.in, tt.si, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// hashAlgorithms maps --hash names to constructors
+var hashAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// hashFile streams a file through h without loading it into memory, and
+// returns the hash with the number of bytes read
+func hashFile(path string, newHash func() hash.Hash) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := newHash()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// writeManifest writes one "<hash>  <path>" line per file, sorted by path, in the
+// format read by sha256sum -c and friends. With withSizes each line is
+// "<hash>  <size>  <path>" instead, the size being the bytes hashed, which
+// those tools cannot read. Unreadable files are recorded as scan errors
+// rather than aborting the manifest.
+func writeManifest(output string, stats *scan.Stats, algorithm, redactRoot string, withSizes bool) error {
+	newHash, ok := hashAlgorithms[algorithm]
+	if !ok {
+		return fmt.Errorf("unknown hash algorithm %q (use md5, sha1, sha256 or sha512)", algorithm)
+	}
+
+	var paths []string
+	if err := stats.EachFile(func(_ string, f scan.FileDetail) {
+		paths = append(paths, f.Path)
+	}); err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	out, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("error creating manifest: %w", err)
+	}
+	// only for the early returns; the final Close is checked below, since a
+	// failed close can leave the manifest truncated
+	defer out.Close()
+
+	w := bufio.NewWriter(out)
+	for _, p := range paths {
+		sum, size, err := hashFile(p, newHash)
+		if err != nil {
+			stats.AddError(p, err)
+			continue
+		}
+		if withSizes {
+			fmt.Fprintf(w, "%s  %d  %s\n", sum, size, displayPath(p, redactRoot))
+		} else {
+			fmt.Fprintf(w, "%s  %s\n", sum, displayPath(p, redactRoot))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("error writing manifest: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("error writing manifest: %w", err)
+	}
+	return nil
+}
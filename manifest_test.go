@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+func TestWriteManifest(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"b.txt": "hello\n", "sub/a.txt": ""} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats, err := scan.Scan(root, scan.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		withSizes bool
+		want      string
+	}{
+		{false, "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03  ./b.txt\n" +
+			"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  ./sub/a.txt\n"},
+		{true, "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03  6  ./b.txt\n" +
+			"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  0  ./sub/a.txt\n"},
+	}
+	for _, tt := range tests {
+		output := filepath.Join(t.TempDir(), "manifest")
+		if err := writeManifest(output, stats, "sha256", root, tt.withSizes); err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("manifest with sizes=%v:\n%s\nwant:\n%s", tt.withSizes, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markdownCell escapes text for a GitHub-flavored Markdown table cell
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// printMarkdown prints the summary as a GitHub-flavored Markdown table, in
+// summary order. Percentages are of totalSize, as with --percent. With total,
+// a bold total row is appended.
+func printMarkdown(sortedExtensions []string, sizes map[string]int64, counts map[string]int, totalSize int64, total bool) {
+	fmt.Println("| Extension | Size | Files | Percent |")
+	fmt.Println("| --- | ---: | ---: | ---: |")
+
+	percent := func(size int64) string {
+		if totalSize <= 0 {
+			return "0.0%"
+		}
+		return fmt.Sprintf("%.1f%%", float64(size)*100/float64(totalSize))
+	}
+
+	var totalCount int
+	for _, ext := range sortedExtensions {
+		fmt.Printf("| %s | %s | %s | %s |\n", markdownCell(extLabel(ext)), formatSize(sizes[ext]), formatCount(int64(counts[ext])), percent(sizes[ext]))
+		totalCount += counts[ext]
+	}
+	if total {
+		fmt.Printf("| **Total** | **%s** | **%s** | **%s** |\n", formatSize(totalSize), formatCount(int64(totalCount)), percent(totalSize))
+	}
+}
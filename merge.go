@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// defaultMerges are the extension aliases folded together unless
+// --no-default-merges is given: alias -> canonical extension
+var defaultMerges = map[string]string{
+	"yml":  "yaml",
+	"htm":  "html",
+	"jpeg": "jpg",
+	"tif":  "tiff",
+	"mpeg": "mpg",
+}
+
+// parseMerges builds the alias -> canonical map from the defaults (when
+// withDefaults) and the --merge values, each "alias=canonical" or
+// "alias1,alias2=canonical". Later values win. Chains such as a=b and b=c
+// resolve to c; a cycle is an error.
+func parseMerges(specs []string, withDefaults, caseSensitive bool) (map[string]string, error) {
+	normalize := func(ext string) string {
+		ext = strings.TrimPrefix(strings.TrimSpace(ext), ".")
+		if !caseSensitive {
+			ext = strings.ToLower(ext)
+		}
+		return ext
+	}
+
+	merges := make(map[string]string)
+	if withDefaults {
+		for from, to := range defaultMerges {
+			merges[from] = to
+		}
+	}
+	for _, spec := range specs {
+		froms, to, ok := strings.Cut(spec, "=")
+		to = normalize(to)
+		if !ok || to == "" {
+			return nil, fmt.Errorf("invalid --merge %q: expected alias=extension", spec)
+		}
+		for _, from := range strings.Split(froms, ",") {
+			from = normalize(from)
+			if from == "" {
+				return nil, fmt.Errorf("invalid --merge %q: expected alias=extension", spec)
+			}
+			if from != to {
+				merges[from] = to
+			}
+		}
+	}
+
+	// resolve chains, in a fixed order so the reported cycle is stable
+	froms := make([]string, 0, len(merges))
+	for from := range merges {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+	resolved := make(map[string]string, len(merges))
+	for _, from := range froms {
+		to, seen := merges[from], map[string]bool{from: true}
+		for {
+			next, ok := merges[to]
+			if !ok {
+				break
+			}
+			if seen[to] {
+				return nil, fmt.Errorf("invalid --merge: %s is merged into itself", from)
+			}
+			seen[to] = true
+			to = next
+		}
+		resolved[from] = to
+	}
+	return resolved, nil
+}
+
+// mergeOf maps a stats key (an extension) to the extension it is merged into
+func mergeOf(merges map[string]string) func(string) string {
+	return func(ext string) string {
+		if to, ok := merges[ext]; ok {
+			return to
+		}
+		return ext
+	}
+}
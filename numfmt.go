@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// digitSeparator groups the digits of counts and --bytes sizes in threes
+// (set from --locale or the environment); "" prints plain digits
+var digitSeparator = ","
+
+// localeSeparators maps a language, or a language_REGION, to its thousands separator
+var localeSeparators = map[string]string{
+	"en": ",", "ja": ",", "ko": ",", "zh": ",", "he": ",", "th": ",", "hi": ",",
+	"de": ".", "es": ".", "it": ".", "nl": ".", "pt": ".", "da": ".", "id": ".", "tr": ".", "el": ".", "ro": ".",
+	"fr": " ", "ru": " ", "pl": " ", "cs": " ", "sk": " ", "sv": " ", "fi": " ", "nb": " ", "uk": " ", "hu": " ",
+	"de_ch": "'", "fr_ch": "'", "it_ch": "'",
+	"none": "",
+}
+
+// separatorFor returns the thousands separator for a locale such as "de",
+// "de_CH", "fr-FR" or "en_US.UTF-8"; "none" turns grouping off
+func separatorFor(locale string) (string, bool) {
+	locale = strings.ToLower(locale)
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	locale = strings.ReplaceAll(locale, "-", "_")
+	if sep, ok := localeSeparators[locale]; ok {
+		return sep, true
+	}
+	lang, _, _ := strings.Cut(locale, "_")
+	sep, ok := localeSeparators[lang]
+	return sep, ok
+}
+
+// setLocale sets digitSeparator from --locale, or else from LC_ALL,
+// LC_NUMERIC or LANG; a missing, C/POSIX or unknown environment locale keeps
+// comma grouping
+func setLocale(flag string) error {
+	if flag != "" {
+		sep, ok := separatorFor(flag)
+		if !ok {
+			return fmt.Errorf("unknown --locale %q (e.g. en, de, fr, de_CH or none)", flag)
+		}
+		digitSeparator = sep
+		return nil
+	}
+	for _, name := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			if sep, ok := separatorFor(v); ok {
+				digitSeparator = sep
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// formatCount renders n with its digits grouped by digitSeparator, e.g. "12,340"
+func formatCount(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	if digitSeparator == "" {
+		return s
+	}
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+	if len(s) <= 3 {
+		return sign + s
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	head := len(s) % 3
+	if head > 0 {
+		b.WriteString(s[:head])
+	}
+	for i := head; i < len(s); i += 3 {
+		if b.Len() > len(sign) {
+			b.WriteString(digitSeparator)
+		}
+		b.WriteString(s[i : i+3])
+	}
+	return b.String()
+}
@@ -0,0 +1,153 @@
+package scan
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// archiveKind identifies which reader handles an archive root
+type archiveKind int
+
+const (
+	archiveNone archiveKind = iota
+	archiveTar
+	archiveTarGz
+	archiveTarBz2
+	archiveZip
+)
+
+// detectArchive reports which archive reader applies to path, based on its name
+func detectArchive(path string) archiveKind {
+	name := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(name, ".tar"):
+		return archiveTar
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return archiveTarGz
+	case strings.HasSuffix(name, ".tar.bz2"), strings.HasSuffix(name, ".tbz2"):
+		return archiveTarBz2
+	case strings.HasSuffix(name, ".zip"):
+		return archiveZip
+	default:
+		return archiveNone
+	}
+}
+
+// IsArchiveRoot reports whether the search root is a regular file we can read as an archive
+func IsArchiveRoot(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return detectArchive(path) != archiveNone
+}
+
+// matchesExtensions mirrors fd's -e filtering for entries we list ourselves.
+// fd always ignores case; caseSensitive requires an exact match instead.
+func matchesExtensions(name, extensions string, caseSensitive bool) bool {
+	if extensions == "" {
+		return true
+	}
+	if !caseSensitive {
+		name = strings.ToLower(name)
+	}
+	for _, ext := range strings.Split(extensions, ",") {
+		ext = strings.TrimSpace(ext)
+		if !caseSensitive {
+			ext = strings.ToLower(ext)
+		}
+		if ext != "" && strings.HasSuffix(name, "."+ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanArchive fills Stats from the entries of an archive, without extracting it,
+// keying each entry by key.
+// Entry paths are reported under the archive path so folder aggregation keeps the
+// archive's internal directory layout.
+func scanArchive(ctx context.Context, archivePath string, filter Filter, stats *Stats, key func(string) string) error {
+	kind := detectArchive(archivePath)
+	if kind == archiveZip {
+		return scanZip(ctx, archivePath, filter, stats, key)
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("error opening archive: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	switch kind {
+	case archiveTarGz:
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("error reading gzip stream: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	case archiveTarBz2:
+		r = bzip2.NewReader(f)
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading tar entry: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		addArchiveEntry(archivePath, hdr.Name, hdr.Size, hdr.ModTime, filter, stats, key)
+	}
+	return nil
+}
+
+func scanZip(ctx context.Context, archivePath string, filter Filter, stats *Stats, key func(string) string) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("error opening archive: %w", err)
+	}
+	defer zr.Close()
+
+	for _, entry := range zr.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		// uncompressed size, so totals match what extraction would produce
+		addArchiveEntry(archivePath, entry.Name, int64(entry.UncompressedSize64), entry.Modified, filter, stats, key)
+	}
+	return nil
+}
+
+func addArchiveEntry(archivePath, name string, size int64, modTime time.Time, filter Filter, stats *Stats, key func(string) string) {
+	// archive entries always use forward slashes; strip "./" and leading "/"
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" || !filter.matchFile(name, true) || !filter.matchTime(modTime) {
+		return
+	}
+	entryPath := filepath.Join(archivePath, filepath.FromSlash(name))
+	stats.add(key(entryPath), entryPath, size, modTime)
+}
@@ -0,0 +1,67 @@
+package scan_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// benchTree generates a tree of files spread over nested folders, with a
+// few extensions and varying sizes
+func benchTree(b *testing.B) string {
+	b.Helper()
+	exts := []string{"go", "txt", "jpg", "json", "md"}
+	files := make(map[string]int)
+	for i := 0; i < 2000; i++ {
+		name := fmt.Sprintf("d%d/s%d/f%d.%s", i%20, i%7, i, exts[i%len(exts)])
+		files[name] = i % 512
+	}
+	return writeTree(b, files)
+}
+
+// BenchmarkScanNative scans the tree of BenchmarkScanFd with the built-in
+// walker, to compare the two engines
+func BenchmarkScanNative(b *testing.B) {
+	dir := benchTree(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := scan.Scan(dir, scan.Options{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkScanFd scans with fd, statting the listed files serially and with
+// a worker pool; a fixed pool size keeps runs comparable across machines
+func BenchmarkScanFd(b *testing.B) {
+	fd, err := scan.FindFd()
+	if err != nil {
+		b.Skip("fd is not installed")
+	}
+	dir := benchTree(b)
+	for _, jobs := range []int{1, 8} {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := scan.Scan(dir, scan.Options{FdCommand: fd, Jobs: jobs}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkScanNativeMIME sniffs every file of the generated tree with the
+// native walker, one at a time and with a pool of classifiers
+func BenchmarkScanNativeMIME(b *testing.B) {
+	dir := benchTree(b)
+	for _, jobs := range []int{1, 8} {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := scan.Scan(dir, scan.Options{Classify: scan.ClassifyMIME, Jobs: jobs}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package scan
+
+import "io/fs"
+
+// allocatedSize is not available here, so Options.DiskUsage falls back to
+// the apparent size
+func allocatedSize(info fs.FileInfo) (int64, bool) {
+	return 0, false
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package scan
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// allocatedSize is the space info's file occupies on disk: its 512-byte
+// blocks, as du counts them
+func allocatedSize(info fs.FileInfo) (int64, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int64(st.Blocks) * 512, true
+}
@@ -0,0 +1,114 @@
+package scan
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// DefaultMaxExtLength is the longest suffix counted as an extension when
+// ExtensionRules.MaxLength is not set
+const DefaultMaxExtLength = 4
+
+// ExtensionRules decides which file name suffixes count as extensions. By
+// default a suffix is one only if it has at most DefaultMaxExtLength
+// characters, all letters or digits, and at least one letter; anything else
+// ("backup", "sqlite3", "bak~", "123") is counted as "no extension".
+type ExtensionRules struct {
+	MaxLength int  // longest suffix counted as an extension; 0 means DefaultMaxExtLength
+	KeepAll   bool // count every suffix as an extension, however long or odd
+	// SplitUnusual counts files whose suffix is not accepted under
+	// UnusualExtension, keeping "no extension" for names without one
+	SplitUnusual bool
+}
+
+// UnusualExtension is the key for suffixes that are not counted as
+// extensions, with ExtensionRules.SplitUnusual
+const UnusualExtension = "unusual"
+
+func (r ExtensionRules) isStandard(ext string) bool {
+	if r.KeepAll {
+		return ext != ""
+	}
+	maxLength := r.MaxLength
+	if maxLength <= 0 {
+		maxLength = DefaultMaxExtLength
+	}
+	if len(ext) > maxLength {
+		return false
+	}
+	hasLetter := false
+	for _, r := range ext {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			hasLetter = true
+		}
+	}
+	return hasLetter
+}
+
+// Classifier returns the stats key function for r: ClassifyExtension with
+// these rules, or ClassifyExtensionCase with caseSensitive
+func (r ExtensionRules) Classifier(caseSensitive bool) func(filePath string) string {
+	return func(filePath string) string {
+		return classifyExtension(filePath, caseSensitive, r)
+	}
+}
+
+// compoundExtensions are multi-part extensions counted as one key (e.g. "tar.gz")
+// instead of by their last part. Everything else keeps single-extension handling.
+var compoundExtensions = []string{"tar.gz", "tar.bz2", "tar.xz", "tar.zst"}
+
+// ClassifyExtension returns the stats key for a file path: its lower-cased
+// extension, or "no extension" (see ExtensionRules for what counts)
+func ClassifyExtension(filePath string) string {
+	return classifyExtension(filePath, false, ExtensionRules{})
+}
+
+// ClassifyExtensionCase is ClassifyExtension without lower-casing, so "JPG"
+// and "jpg" get separate keys
+func ClassifyExtensionCase(filePath string) string {
+	return classifyExtension(filePath, true, ExtensionRules{})
+}
+
+func classifyExtension(filePath string, caseSensitive bool, rules ExtensionRules) string {
+	base := filepath.Base(filePath)
+	lower := strings.ToLower(base)
+	for _, compound := range compoundExtensions {
+		if strings.HasSuffix(lower, "."+compound) && len(base) > len(compound)+1 {
+			if caseSensitive {
+				return base[len(base)-len(compound):]
+			}
+			return compound
+		}
+	}
+
+	fileExt := filepath.Ext(base)
+	if !caseSensitive {
+		fileExt = strings.ToLower(fileExt)
+	}
+	if fileExt == "" {
+		return "no extension"
+	}
+	fileExt = fileExt[1:] // remove the dot
+	if !rules.isStandard(fileExt) {
+		if rules.SplitUnusual && fileExt != "" {
+			return UnusualExtension
+		}
+		return "no extension"
+	}
+	return fileExt
+}
+
+// fileSuffix is the extension of a base name as ExtensionRules.KeepAll sees
+// it (compound extensions included), lower-cased unless caseSensitive, or ""
+// when the name has none
+func fileSuffix(name string, caseSensitive bool) string {
+	ext := classifyExtension(name, caseSensitive, ExtensionRules{KeepAll: true})
+	if ext == "no extension" {
+		return ""
+	}
+	return ext
+}
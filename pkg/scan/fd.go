@@ -0,0 +1,207 @@
+package scan
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+func findExecutable(names ...string) (string, error) {
+	for _, name := range names {
+		path, err := exec.LookPath(name)
+		if err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("none of the executables were found")
+}
+
+// FindFd returns the path of fd (or fdfind, as Debian names it) in PATH
+func FindFd() (string, error) {
+	return findExecutable("fd", "fdfind")
+}
+
+// FdArgs returns the arguments a scan passes to fd for the directory root
+func FdArgs(root string, filter Filter) []string {
+	return buildFdArgs(root, filter)
+}
+
+// buildFdArgs builds the argument list for fdfind
+func buildFdArgs(path string, filter Filter) []string {
+	// search all files, possibly narrowed by -e and --exclude
+	args := []string{"--type", "f", "--full-path", "--base-directory", path}
+
+	// -H = include hidden files; dropped to let fd skip them
+	if !filter.NoHidden {
+		args = append(args, "-H")
+	}
+
+	// -I = don't respect ignore files; dropped to let fd apply .gitignore,
+	// .ignore and .fdignore
+	if !filter.Gitignore && !filter.RespectIgnore {
+		args = append(args, "-I")
+	}
+
+	// -L = follow symlinks; fd detects loops itself
+	if filter.FollowSymlinks {
+		args = append(args, "-L")
+	}
+
+	if filter.MaxDepth > 0 {
+		args = append(args, "--max-depth", strconv.Itoa(filter.MaxDepth))
+	}
+
+	for _, pattern := range filter.Excludes {
+		args = append(args, "--exclude", pattern)
+	}
+
+	if filter.Extensions == "" {
+		return args
+	}
+
+	extensionList := strings.Split(filter.Extensions, ",")
+	for _, ext := range extensionList {
+		ext = strings.TrimSpace(ext)
+		if ext != "" {
+			args = append(args, "-e", ext)
+		}
+	}
+	return args
+}
+
+// errNotRegular is recorded for listed paths that are directories, devices and the like
+var errNotRegular = errors.New("not a regular file")
+
+// statResult is the outcome of statting one path reported by fd
+type statResult struct {
+	path string
+	key  string
+	info os.FileInfo
+	err  error
+}
+
+// statFiles stats (and classifies) every path that feed sends, up to jobs
+// files at once, and records them in stats. Results are merged on the calling
+// goroutine only, so the maps need no locking. Paths that cannot be statted
+// and paths that are not regular files are recorded with Stats.AddError.
+func statFiles(stats *Stats, filter Filter, jobs int, classify func(string) string, feed func(paths chan<- string)) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	paths := make(chan string, jobs*4)
+	results := make(chan statResult, jobs*4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for filePath := range paths {
+				info, err := os.Stat(filePath)
+				r := statResult{path: filePath, info: info, err: err}
+				if err == nil {
+					r.key = classify(filePath)
+				}
+				results <- r
+			}
+		}()
+	}
+
+	go func() {
+		feed(paths)
+		close(paths)
+		wg.Wait()
+		close(results)
+	}()
+
+	for r := range results {
+		if r.err != nil {
+			stats.AddError(r.path, r.err)
+			continue
+		}
+		if !r.info.Mode().IsRegular() {
+			// fd only lists files, but a file list may name anything
+			stats.AddError(r.path, errNotRegular)
+			continue
+		}
+		if !filter.matchInfo(r.info) || !stats.firstLink(r.info) {
+			continue
+		}
+
+		stats.add(r.key, r.path, stats.sizeOf(r.info), r.info.ModTime())
+	}
+}
+
+// scanFiles runs fdfind and fills Stats, statting (and classifying) up to jobs files at once
+func scanFiles(ctx context.Context, fdCmdName, path string, stats *Stats, cmdArgs []string, filter Filter, jobs int, classify func(string) string, warn func(line string)) error {
+	// the context kills fd on timeout or Ctrl-C
+	fdCmd := exec.CommandContext(ctx, fdCmdName, cmdArgs...)
+
+	stdout, err := fdCmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("error obtaining stdout: %w", err)
+	}
+	stderr, err := fdCmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("error obtaining stderr: %w", err)
+	}
+
+	if err := fdCmd.Start(); err != nil {
+		return fmt.Errorf("error starting command: %w", err)
+	}
+
+	// collects fdfind stderr in a goroutine; it must finish before
+	// fdCmd.Wait closes the pipe
+	var stderrLines []string
+	var stderrDone sync.WaitGroup
+	stderrDone.Add(1)
+	go func() {
+		defer stderrDone.Done()
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			stderrLines = append(stderrLines, scanner.Text())
+		}
+	}()
+
+	statFiles(stats, filter, jobs, classify, func(paths chan<- string) {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			relativePath := scanner.Text()
+			// fd's -e ignores case and fd knows nothing of ExtRegex or
+			// NameGlobs, so those are checked here
+			if (filter.CaseSensitive || filter.ExtRegex != nil || len(filter.NameGlobs) > 0) && !filter.matchName(filepath.Base(relativePath)) {
+				continue
+			}
+			paths <- filepath.Join(path, relativePath)
+		}
+	})
+
+	stderrDone.Wait()
+	if err := fdCmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// a failed run reports fd's stderr only in the error
+		if len(stderrLines) > 0 {
+			return fmt.Errorf("command execution failed: %w: %s", err, strings.Join(stderrLines, "; "))
+		}
+		return fmt.Errorf("command execution failed: %w", err)
+	}
+
+	// warnings from a successful run go to warn (stderr by default), keeping
+	// stdout clean for JSON and CSV output
+	if warn != nil {
+		for _, line := range stderrLines {
+			warn(line)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package scan_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// fakeFd writes a shell script that stands in for fd and returns its path
+func fakeFd(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	path := filepath.Join(t.TempDir(), "fd")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFdErrorIncludesStderr(t *testing.T) {
+	dir := writeTree(t, map[string]int{"a.txt": 1})
+	fd := fakeFd(t, "echo 'bad pattern: [z-a]' >&2\necho 'see --help' >&2\nexit 2\n")
+
+	_, err := scan.Scan(dir, scan.Options{FdCommand: fd})
+	if err == nil {
+		t.Fatal("Scan succeeded, want the fd failure")
+	}
+	if msg := err.Error(); !strings.Contains(msg, "bad pattern: [z-a]; see --help") || !strings.Contains(msg, "exit status 2") {
+		t.Errorf("error %q does not include fd's stderr and exit status", msg)
+	}
+}
+
+func TestFdWarningsOnSuccess(t *testing.T) {
+	dir := writeTree(t, map[string]int{"a.txt": 3, "sub/b.go": 5})
+	fd := fakeFd(t, "echo a.txt\necho sub/b.go\necho 'permission denied: private' >&2\n")
+
+	var warnings []string
+	stats, err := scan.Scan(dir, scan.Options{FdCommand: fd, FdWarning: func(line string) { warnings = append(warnings, line) }})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if want := map[string]int64{"txt": 3, "go": 5}; !reflect.DeepEqual(stats.Sizes, want) {
+		t.Errorf("Sizes = %v, want %v", stats.Sizes, want)
+	}
+	if want := []string{"permission denied: private"}; !reflect.DeepEqual(warnings, want) {
+		t.Errorf("warnings = %q, want %q", warnings, want)
+	}
+
+	// QuietErrors drops them
+	warnings = nil
+	if _, err := scan.Scan(dir, scan.Options{FdCommand: fd, QuietErrors: true, FdWarning: func(line string) { warnings = append(warnings, line) }}); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %q with QuietErrors, want none", warnings)
+	}
+}
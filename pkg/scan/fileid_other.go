@@ -0,0 +1,21 @@
+//go:build !unix
+
+package scan
+
+import "io/fs"
+
+// fileID identifies a file or directory independently of the path used to reach it
+type fileID struct {
+	dev, ino uint64
+}
+
+// fileIDOf is not available here, so followed symlinks to directories are
+// never descended into (see scanNative)
+func fileIDOf(info fs.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
+
+// hardlinkID is not available here either, so every link is counted
+func hardlinkID(info fs.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
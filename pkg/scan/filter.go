@@ -0,0 +1,252 @@
+package scan
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter holds the file selection options shared by every scan engine.
+// The zero value matches every file.
+type Filter struct {
+	Extensions string   // comma-separated extension list (like fd -e), empty for all
+	Excludes   []string // glob patterns relative to the root, OR-combined
+	Gitignore  bool     // honour .gitignore files
+	NoHidden   bool     // skip dotfiles and everything under dot-directories
+	MaxDepth   int      // 0 = unlimited; 1 = only files directly under the root
+
+	// RespectIgnore honours every ignore file fd knows (.gitignore, .ignore
+	// and .fdignore), as fd does when -I is not passed
+	RespectIgnore bool
+	// GitignoreInReposOnly only applies .gitignore files inside a git
+	// repository (see FindRepoRoot), as fd does; .ignore and .fdignore still
+	// apply everywhere
+	GitignoreInReposOnly bool
+
+	// FollowSymlinks counts the targets of symlinks and descends into
+	// symlinked directories; otherwise symlinks are skipped
+	FollowSymlinks bool
+
+	// CaseSensitive matches Extensions exactly and keeps "JPG" and "jpg" as
+	// separate keys (when Options.Classify is not set)
+	CaseSensitive bool
+
+	// ExtRegex, when set, only counts files whose extension (lower-cased
+	// unless CaseSensitive, without the leading dot, "" for none) matches
+	ExtRegex *regexp.Regexp
+	// NameGlobs, when set, only counts files whose base name matches one of
+	// these path.Match patterns (e.g. "*-test.go", "Dockerfile*"), OR-combined
+	NameGlobs []string
+
+	// Owner, when set, only counts files owned by this numeric uid (see
+	// LookupOwner); files whose owner cannot be read never match
+	Owner string
+
+	ModifiedAfter  time.Time // zero = no lower bound
+	ModifiedBefore time.Time // zero = no upper bound
+}
+
+// matchTime reports whether a modification time falls inside the
+// ModifiedAfter / ModifiedBefore window (after <= t < before)
+func (f Filter) matchTime(t time.Time) bool {
+	if !f.ModifiedAfter.IsZero() && t.Before(f.ModifiedAfter) {
+		return false
+	}
+	if !f.ModifiedBefore.IsZero() && !t.Before(f.ModifiedBefore) {
+		return false
+	}
+	return true
+}
+
+// ParseAge parses an age such as "36h", "7d", "2w", "6mo" or "1y".
+// Months are 30 days and years 365 days.
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	units := []struct {
+		suffix string
+		unit   time.Duration
+	}{
+		{"mo", 30 * 24 * time.Hour},
+		{"h", time.Hour},
+		{"d", 24 * time.Hour},
+		{"w", 7 * 24 * time.Hour},
+		{"y", 365 * 24 * time.Hour},
+	}
+	for _, u := range units {
+		if !strings.HasSuffix(s, u.suffix) {
+			continue
+		}
+		n, err := strconv.ParseFloat(strings.TrimSuffix(s, u.suffix), 64)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n * float64(u.unit)), nil
+	}
+	return 0, fmt.Errorf("invalid age %q: unit must be h, d, w, mo or y", s)
+}
+
+// ParseTimeBound parses an RFC3339 timestamp, a date (2006-01-02), or an age
+// such as "30d" or "6mo" meaning that long before now
+func ParseTimeBound(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	age, err := ParseAge(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use RFC3339, YYYY-MM-DD, or an age like 30d", s)
+	}
+	return now.Add(-age), nil
+}
+
+// matchInfo reports whether a statted file passes the time window and Owner
+func (f Filter) matchInfo(info fs.FileInfo) bool {
+	if !f.matchTime(info.ModTime()) {
+		return false
+	}
+	if f.Owner != "" {
+		uid, ok := fileOwner(info)
+		if !ok || uid != f.Owner {
+			return false
+		}
+	}
+	return true
+}
+
+// skipDir reports whether a directory (relative to the root, slash-separated) should be pruned
+func (f Filter) skipDir(rel string) bool {
+	// files below a directory at MaxDepth would be deeper than the limit
+	if f.MaxDepth > 0 && rel != "." && depth(rel) >= f.MaxDepth {
+		return true
+	}
+	if f.NoHidden && rel != "." && isHidden(path.Base(rel)) {
+		return true
+	}
+	return isExcluded(rel, f.Excludes)
+}
+
+// isHidden reports whether a file or directory name is a dotfile
+func isHidden(name string) bool {
+	return len(name) > 1 && name[0] == '.' && name != ".."
+}
+
+// hasHiddenPart reports whether any component of a slash-separated path is hidden
+func hasHiddenPart(rel string) bool {
+	for _, part := range strings.Split(rel, "/") {
+		if isHidden(part) {
+			return true
+		}
+	}
+	return false
+}
+
+// depth is the number of components in a slash-separated relative path
+func depth(rel string) int {
+	return strings.Count(rel, "/") + 1
+}
+
+// matchFile reports whether a file (relative to the root, slash-separated) should be counted.
+// checkParents also tests every parent directory, for callers that cannot prune
+// with skipDir while listing (such as archive readers).
+func (f Filter) matchFile(rel string, checkParents bool) bool {
+	if f.MaxDepth > 0 && depth(rel) > f.MaxDepth {
+		return false
+	}
+	if checkParents && isExcludedOrUnder(rel, f.Excludes) {
+		return false
+	}
+	if !checkParents && isExcluded(rel, f.Excludes) {
+		return false
+	}
+	if f.NoHidden && (checkParents && hasHiddenPart(rel) || isHidden(path.Base(rel))) {
+		return false
+	}
+	return f.matchName(path.Base(rel))
+}
+
+// matchName reports whether a base file name passes Extensions, ExtRegex and NameGlobs
+func (f Filter) matchName(name string) bool {
+	if len(f.NameGlobs) > 0 && !matchesAnyGlob(name, f.NameGlobs) {
+		return false
+	}
+	if !matchesExtensions(name, f.Extensions, f.CaseSensitive) {
+		return false
+	}
+	if f.ExtRegex != nil && !f.ExtRegex.MatchString(fileSuffix(name, f.CaseSensitive)) {
+		return false
+	}
+	return true
+}
+
+// matchesAnyGlob reports whether name matches at least one of the patterns
+func matchesAnyGlob(name string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isExcluded reports whether rel, a slash-separated path relative to the scan
+// root, matches any exclude pattern. Patterns without a "/" match a single
+// path component anywhere in the tree (e.g. "node_modules", "*.log"); patterns
+// with a "/" are matched against the whole relative path, where "**" matches
+// any number of directories. Only rel itself is tested, so callers walking a
+// tree should test each directory as they descend.
+func isExcluded(rel string, patterns []string) bool {
+	if rel == "" || rel == "." {
+		return false
+	}
+	for _, p := range patterns {
+		if !strings.Contains(p, "/") {
+			if ok, _ := path.Match(p, path.Base(rel)); ok {
+				return true
+			}
+			continue
+		}
+		if matchSegments(strings.Split(strings.Trim(p, "/"), "/"), strings.Split(rel, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// isExcludedOrUnder reports whether rel or any of its parent directories is excluded
+func isExcludedOrUnder(rel string, patterns []string) bool {
+	for p := rel; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+		if isExcluded(p, patterns) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path components against pattern components, with "**"
+// standing for zero or more components
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
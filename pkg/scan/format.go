@@ -0,0 +1,34 @@
+package scan
+
+import "fmt"
+
+// FormatSize renders a byte count in binary units, e.g. "1.50 MiB" or "512 bytes"
+func FormatSize(size int64) string {
+	return FormatSizeAs(size, false, false)
+}
+
+// FormatSizeAs renders a byte count in decimal units (KB = 1000 bytes) when si
+// is set, or binary units (KiB = 1024 bytes) otherwise. aligned pads the
+// result so decimal points line up in a column.
+func FormatSizeAs(size int64, si, aligned bool) string {
+	base, units := int64(1024), []string{"KiB", "MiB", "GiB", "TiB"}
+	if si {
+		base, units = 1000, []string{"KB", "MB", "GB", "TB"}
+	}
+
+	// aligned: up to 4 integer digits, and byte counts leave room for ".00"
+	numFmt, byteFmt := "%.2f", "%d bytes"
+	if aligned {
+		numFmt, byteFmt = "%7.2f", "%4d    bytes"
+	}
+
+	if size < base {
+		return fmt.Sprintf(byteFmt, size)
+	}
+	unit, div := 0, base
+	for unit < len(units)-1 && size >= div*base {
+		unit++
+		div *= base
+	}
+	return fmt.Sprintf(numFmt+" "+units[unit], float64(size)/float64(div))
+}
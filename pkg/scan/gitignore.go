@@ -0,0 +1,151 @@
+package scan
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule is one line of a .gitignore file
+type ignoreRule struct {
+	segments []string // pattern split on "/", for anchored rules
+	name     string   // pattern matched against the base name, for unanchored rules
+	anchored bool     // pattern contained a "/" so it is relative to the .gitignore's directory
+	negate   bool     // "!" re-includes a previously ignored path
+	dirOnly  bool     // trailing "/" only matches directories
+}
+
+// ignoreMatcher applies the .gitignore files found while walking a tree.
+// Rules are kept per directory (slash-separated, relative to the root, "." for
+// the root) and only apply to paths below that directory.
+type ignoreMatcher struct {
+	rules map[string][]ignoreRule
+	files []string // ignore file names read in each directory, lowest precedence first
+
+	// requireGit skips .gitignore files outside a repository; repos holds
+	// the directories (as rules keys) found to be inside one
+	requireGit bool
+	repos      map[string]bool
+}
+
+// gitignoreFiles is what Filter.Gitignore reads; fdIgnoreFiles adds the
+// other files fd honours without -I, for Filter.RespectIgnore
+var (
+	gitignoreFiles = []string{".gitignore"}
+	fdIgnoreFiles  = []string{".gitignore", ".ignore", ".fdignore"}
+)
+
+func newIgnoreMatcher(files []string) *ignoreMatcher {
+	return &ignoreMatcher{rules: make(map[string][]ignoreRule), files: files}
+}
+
+// requireRepo makes the matcher skip .gitignore files outside a git
+// repository, the walk's root being inside one when rootInRepo is set
+func (m *ignoreMatcher) requireRepo(rootInRepo bool) {
+	m.requireGit = true
+	m.repos = map[string]bool{".": rootInRepo}
+}
+
+// inRepo reports whether rel, whose directory on disk is dir, lies inside a
+// git repository; parents are always loaded before their subdirectories
+func (m *ignoreMatcher) inRepo(dir, rel string) bool {
+	in := m.repos[rel] || (rel != "." && m.repos[path.Dir(rel)]) || isRepoRoot(dir)
+	m.repos[rel] = in
+	return in
+}
+
+// load reads the ignore files in dir, if there are any, as the rules for rel.
+// Rules from later files override earlier ones, like later lines do.
+func (m *ignoreMatcher) load(dir, rel string) {
+	skipGitignore := m.requireGit && !m.inRepo(dir, rel)
+	var rules []ignoreRule
+	for _, name := range m.files {
+		if skipGitignore && name == ".gitignore" {
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if rule, ok := parseIgnoreLine(scanner.Text()); ok {
+				rules = append(rules, rule)
+			}
+		}
+		f.Close()
+	}
+	if len(rules) > 0 {
+		m.rules[rel] = rules
+	}
+}
+
+func parseIgnoreLine(line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		// "\#" and "\!" escape a leading special character
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		rule.segments = strings.Split(strings.TrimPrefix(line, "/"), "/")
+	} else {
+		rule.name = line
+	}
+	return rule, true
+}
+
+// ignored reports whether rel (slash-separated, relative to the root) is ignored.
+// Rules from shallower directories are applied first, so deeper .gitignore files
+// and later lines override earlier ones, and the last matching rule wins.
+func (m *ignoreMatcher) ignored(rel string, isDir bool) bool {
+	// directories that can hold rules for rel, from the root down
+	dirs := []string{"."}
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		dirs = append(dirs, strings.Join(parts[:i], "/"))
+	}
+
+	ignored := false
+	for _, dir := range dirs {
+		sub := rel
+		if dir != "." {
+			sub = strings.TrimPrefix(rel, dir+"/")
+		}
+		for _, rule := range m.rules[dir] {
+			if rule.dirOnly && !isDir {
+				continue
+			}
+			if rule.matches(sub) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	return ignored
+}
+
+func (r ignoreRule) matches(sub string) bool {
+	if r.anchored {
+		return matchSegments(r.segments, strings.Split(sub, "/"))
+	}
+	ok, _ := path.Match(r.name, path.Base(sub))
+	return ok
+}
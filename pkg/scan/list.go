@@ -0,0 +1,59 @@
+package scan
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// ScanList records the files named in r, one path per line, instead of
+// walking a root; fd is never run. Relative paths are resolved against base.
+// Blank lines and lines starting with # are skipped. Filter applies as if
+// base were the scan root, and paths are statted Options.Jobs at once.
+// Missing or unreadable entries, and entries that are not regular files, are
+// recorded in Stats.Errors. Cancelling ctx stops reading with ctx.Err().
+func ScanList(ctx context.Context, r io.Reader, base string, opts Options) (*Stats, error) {
+	stats, classify, _, err := prepare(opts)
+	if err != nil {
+		return stats, err
+	}
+	filter := opts.Filter
+
+	var readErr error
+	statFiles(stats, filter, opts.Jobs, classify, func(paths chan<- string) {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if ctx.Err() != nil {
+				return
+			}
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			p := line
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(base, p)
+			}
+			rel, err := filepath.Rel(base, p)
+			if err != nil || strings.HasPrefix(rel, "..") {
+				rel = p // outside base: match against the path as given
+			}
+			if !filter.matchFile(filepath.ToSlash(rel), true) {
+				continue
+			}
+			paths <- p
+		}
+		readErr = scanner.Err()
+	})
+
+	if err := ctx.Err(); err != nil {
+		return stats, err
+	}
+	if readErr != nil {
+		return stats, fmt.Errorf("error reading file list: %w", readErr)
+	}
+	return stats, nil
+}
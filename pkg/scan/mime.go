@@ -0,0 +1,31 @@
+package scan
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ClassifyMIME returns the sniffed MIME type of a file, without parameters
+// (e.g. "text/plain" rather than "text/plain; charset=utf-8"). It reads at most
+// the first 512 bytes; files that cannot be read fall back to their extension.
+func ClassifyMIME(filePath string) string {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return ClassifyExtension(filePath)
+	}
+	defer f.Close()
+
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return ClassifyExtension(filePath)
+	}
+
+	mime := http.DetectContentType(buf[:n])
+	if i := strings.IndexByte(mime, ';'); i >= 0 {
+		mime = mime[:i]
+	}
+	return mime
+}
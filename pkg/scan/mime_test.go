@@ -0,0 +1,48 @@
+package scan_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+func TestScanNativeMIMEJobs(t *testing.T) {
+	dir := t.TempDir()
+	contents := map[string]string{
+		"a.bin":       "\x89PNG\r\n\x1a\n0000",
+		"b.txt":       "plain text\n",
+		"sub/c.dat":   "%PDF-1.4 rest",
+		"sub/d.bin":   "more plain text\n",
+		"sub/x/e.png": "\x89PNG\r\n\x1a\n1111",
+	}
+	for name, content := range contents {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a broken link is recorded as an error by the pool as well
+	if err := os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "broken")); err != nil {
+		t.Skip("symlinks are not supported here")
+	}
+
+	want := map[string]int64{"image/png": 24, "text/plain": 27, "application/pdf": 13}
+	for _, jobs := range []int{1, 4} {
+		stats := scanTree(t, dir, scan.Options{Classify: scan.ClassifyMIME, Jobs: jobs, Filter: scan.Filter{FollowSymlinks: true}})
+		if !reflect.DeepEqual(stats.Sizes, want) {
+			t.Errorf("jobs=%d: Sizes = %v, want %v", jobs, stats.Sizes, want)
+		}
+		if got := relFiles(t, dir, stats)["text/plain"]; !reflect.DeepEqual(got, []fileSize{{"b.txt", 11}, {"sub/d.bin", 16}}) {
+			t.Errorf("jobs=%d: text/plain files = %v", jobs, got)
+		}
+		if stats.Skipped != 1 || len(stats.Errors) != 1 || relPath(t, dir, stats.Errors[0].Path) != "broken" {
+			t.Errorf("jobs=%d: Skipped = %d, Errors = %v, want the broken link", jobs, stats.Skipped, stats.Errors)
+		}
+	}
+}
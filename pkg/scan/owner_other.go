@@ -0,0 +1,28 @@
+//go:build !unix
+
+package scan
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// OwnersSupported reports whether ClassifyOwner and Filter.Owner can tell
+// file owners apart here
+const OwnersSupported = false
+
+// fileOwner is not available here
+func fileOwner(info fs.FileInfo) (string, bool) {
+	return "", false
+}
+
+// ClassifyOwner cannot read owners on this platform, so it keys files by
+// extension like ClassifyExtension
+func ClassifyOwner(filePath string) string {
+	return ClassifyExtension(filePath)
+}
+
+// LookupOwner always fails here, since owners cannot be compared
+func LookupOwner(owner string) (string, error) {
+	return "", fmt.Errorf("file owners are not supported on this platform")
+}
@@ -0,0 +1,65 @@
+//go:build unix
+
+package scan
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+// OwnersSupported reports whether ClassifyOwner and Filter.Owner can tell
+// file owners apart here
+const OwnersSupported = true
+
+// ownerNames caches uid -> user name lookups; classifiers run on several goroutines
+var ownerNames sync.Map
+
+// fileOwner returns the numeric uid owning info's file
+func fileOwner(info fs.FileInfo) (string, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", false
+	}
+	return strconv.FormatUint(uint64(st.Uid), 10), true
+}
+
+// ClassifyOwner returns the name of the user owning a file, or its numeric
+// uid when the name cannot be resolved. Files that cannot be statted fall
+// back to their extension.
+func ClassifyOwner(filePath string) string {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return ClassifyExtension(filePath)
+	}
+	uid, ok := fileOwner(info)
+	if !ok {
+		return ClassifyExtension(filePath)
+	}
+	if name, ok := ownerNames.Load(uid); ok {
+		return name.(string)
+	}
+	name := uid
+	if u, err := user.LookupId(uid); err == nil {
+		name = u.Username
+	}
+	ownerNames.Store(uid, name)
+	return name
+}
+
+// LookupOwner resolves a user name, or a numeric uid, to the uid that
+// Filter.Owner expects
+func LookupOwner(owner string) (string, error) {
+	if _, err := strconv.ParseUint(owner, 10, 32); err == nil {
+		return owner, nil
+	}
+	u, err := user.Lookup(owner)
+	if err != nil {
+		return "", fmt.Errorf("unknown user %q", owner)
+	}
+	return u.Uid, nil
+}
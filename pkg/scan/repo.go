@@ -0,0 +1,31 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// FindRepoRoot returns the closest directory at or above dir that holds a
+// .git entry (a directory, or a file as in worktrees and submodules)
+func FindRepoRoot(dir string) (string, bool) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if isRepoRoot(abs) {
+			return abs, true
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return "", false
+		}
+		abs = parent
+	}
+}
+
+// isRepoRoot reports whether dir itself holds a .git entry
+func isRepoRoot(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil
+}
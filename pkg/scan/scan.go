@@ -0,0 +1,123 @@
+// Package scan walks a directory tree (with fd or a built-in walker), or lists
+// a tar/zip archive, and aggregates file sizes per extension. It is the engine
+// behind the extdust command.
+package scan
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Options configures a scan. The zero value counts every file under the root
+// with the native walker.
+type Options struct {
+	Filter         Filter
+	ExtensionRules ExtensionRules // which suffixes count as extensions; the zero value keeps the defaults
+
+	FdCommand   string                   // fd executable to list files with (see FindFd); empty uses the native walker
+	Jobs        int                      // files statted at once with fd, or classified at once by the native walker with Classify set; values below 1 mean 1
+	Classify    func(path string) string // stats key for a file; nil means ExtensionRules.Classifier
+	Group       func(key string) string  // when set, maps each key (e.g. an extension) to the key it is recorded under
+	QuietErrors bool                     // don't copy warnings from a successful fd run to stderr (unreadable files are always recorded in Stats.Errors)
+	FdWarning   func(line string)        // when set, receives those warnings instead of stderr
+
+	Spill       bool                       // keep per-file details in temporary files instead of memory
+	SpillBudget int                        // records held in memory before a sorted run is written to disk
+	SpillLess   func(a, b FileDetail) bool // order of spilled records; nil means FileLess("size", false)
+
+	Progress func(size int64)    // called for every recorded file, on a single goroutine
+	OnError  func(err ScanError) // called for every entry added to Stats.Errors as it happens, on a single goroutine
+
+	HardlinksOnce bool // count a file with several hardlinks once, under the first path found; a no-op where inodes are unavailable
+	DiskUsage     bool // record allocated blocks instead of file lengths; archive entries and platforms without block counts keep the length
+}
+
+// Scan scans one directory or archive and returns the aggregated stats
+func Scan(path string, opts Options) (*Stats, error) {
+	return ScanRoots(context.Background(), []string{path}, opts)
+}
+
+// ScanRoots scans every root into one Stats, stopping at the first error.
+// Roots that are tar or zip archives have their entries listed instead.
+// Cancelling ctx stops the scan with ctx.Err(); the returned Stats is never
+// nil and holds whatever was recorded up to that point.
+func ScanRoots(ctx context.Context, roots []string, opts Options) (*Stats, error) {
+	stats, classify, entryKey, err := prepare(opts)
+	if err != nil {
+		return stats, err
+	}
+
+	// extension keys come from names alone, so the native walker only uses
+	// a pool for a custom Classify, which may read every file
+	classifyJobs := 1
+	if opts.Classify != nil {
+		classifyJobs = opts.Jobs
+	}
+
+	for _, root := range roots {
+		var err error
+		if IsArchiveRoot(root) {
+			// the root itself is an archive: list its entries instead of running fd
+			err = scanArchive(ctx, root, opts.Filter, stats, entryKey)
+		} else if opts.FdCommand != "" {
+			cmdArgs := buildFdArgs(root, opts.Filter)
+			err = scanFiles(ctx, opts.FdCommand, root, stats, cmdArgs, opts.Filter, opts.Jobs, classify, fdWarning(opts))
+		} else {
+			err = scanNative(ctx, root, opts.Filter, stats, classify, classifyJobs)
+		}
+		if err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// fdWarning returns where warnings from a successful fd run go, nil to drop them
+func fdWarning(opts Options) func(line string) {
+	switch {
+	case opts.QuietErrors:
+		return nil
+	case opts.FdWarning != nil:
+		return opts.FdWarning
+	}
+	return func(line string) { fmt.Fprintf(os.Stderr, "fd: %s\n", line) }
+}
+
+// prepare returns the empty Stats for a scan with opts, and the keys for
+// files on disk (classify) and for archive entries (entryKey)
+func prepare(opts Options) (stats *Stats, classify, entryKey func(string) string, err error) {
+	stats = NewStats()
+	if opts.Spill {
+		less := opts.SpillLess
+		if less == nil {
+			less = FileLess("size", false)
+		}
+		store, err := newSpillStore(opts.SpillBudget, less)
+		if err != nil {
+			return stats, nil, nil, err
+		}
+		stats.spill = store
+	}
+	stats.progress = opts.Progress
+	stats.onError = opts.OnError
+	stats.diskUsage = opts.DiskUsage
+	if opts.HardlinksOnce {
+		stats.links = make(map[fileID]bool)
+	}
+
+	byExtension := opts.ExtensionRules.Classifier(opts.Filter.CaseSensitive)
+	classify = opts.Classify
+	if classify == nil {
+		classify = byExtension
+	}
+	// archive entries cannot be opened for content sniffing, so they are
+	// always keyed by extension
+	entryKey = byExtension
+	if group := opts.Group; group != nil {
+		base := classify
+		classify = func(p string) string { return group(base(p)) }
+		entryKey = func(p string) string { return group(byExtension(p)) }
+	}
+	return stats, classify, entryKey, nil
+}
@@ -0,0 +1,253 @@
+package scan_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// writeTree creates each file (slash-separated, relative to a new temporary
+// directory) with the given number of bytes and returns the directory
+func writeTree(t testing.TB, files map[string]int) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, size := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(strings.Repeat("x", size)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// fileSize is a recorded file with its path relative to the scan root
+type fileSize struct {
+	Path string
+	Size int64
+}
+
+// relFiles returns stats.Files with paths made relative to root and each
+// list ordered by path, so it can be compared independently of walk order
+func relFiles(t *testing.T, root string, stats *scan.Stats) map[string][]fileSize {
+	t.Helper()
+	files := make(map[string][]fileSize)
+	for ext, list := range stats.Files {
+		for _, f := range list {
+			files[ext] = append(files[ext], fileSize{relPath(t, root, f.Path), f.Size})
+		}
+		sort.Slice(files[ext], func(i, j int) bool { return files[ext][i].Path < files[ext][j].Path })
+	}
+	return files
+}
+
+// relFolders returns stats.Folders with the folder keys made relative to root
+func relFolders(t *testing.T, root string, stats *scan.Stats) map[string]map[string]int64 {
+	t.Helper()
+	folders := make(map[string]map[string]int64)
+	for ext, dirs := range stats.Folders {
+		folders[ext] = make(map[string]int64)
+		for dir, size := range dirs {
+			folders[ext][relPath(t, root, dir)] = size
+		}
+	}
+	return folders
+}
+
+func relPath(t *testing.T, root, path string) string {
+	t.Helper()
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// scanTree scans dir with the native walker and fails the test on error
+func scanTree(t *testing.T, dir string, opts scan.Options) *scan.Stats {
+	t.Helper()
+	stats, err := scan.Scan(dir, opts)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	return stats
+}
+
+// checkStats compares Sizes, Counts, Files and Folders with the expected values
+func checkStats(t *testing.T, dir string, stats *scan.Stats, sizes map[string]int64, files map[string][]fileSize, folders map[string]map[string]int64) {
+	t.Helper()
+	if !reflect.DeepEqual(stats.Sizes, sizes) {
+		t.Errorf("Sizes = %v, want %v", stats.Sizes, sizes)
+	}
+	counts := make(map[string]int)
+	for ext, list := range files {
+		counts[ext] = len(list)
+	}
+	if !reflect.DeepEqual(stats.Counts, counts) {
+		t.Errorf("Counts = %v, want %v", stats.Counts, counts)
+	}
+	if got := relFiles(t, dir, stats); !reflect.DeepEqual(got, files) {
+		t.Errorf("Files = %v, want %v", got, files)
+	}
+	if got := relFolders(t, dir, stats); !reflect.DeepEqual(got, folders) {
+		t.Errorf("Folders = %v, want %v", got, folders)
+	}
+}
+
+func TestScanTree(t *testing.T) {
+	dir := writeTree(t, map[string]int{
+		"a.txt":            10,
+		"B.TXT":            5,
+		"Makefile":         4,
+		"notes.backup":     3,
+		"src/main.go":      20,
+		"src/deep/x/y.go":  7,
+		"src/deep/x/z.txt": 1,
+		"empty.go":         0,
+	})
+	stats := scanTree(t, dir, scan.Options{})
+
+	checkStats(t, dir, stats,
+		map[string]int64{"txt": 16, "go": 27, "no extension": 7},
+		map[string][]fileSize{
+			"txt":          {{"B.TXT", 5}, {"a.txt", 10}, {"src/deep/x/z.txt", 1}},
+			"go":           {{"empty.go", 0}, {"src/deep/x/y.go", 7}, {"src/main.go", 20}},
+			"no extension": {{"Makefile", 4}, {"notes.backup", 3}},
+		},
+		map[string]map[string]int64{
+			"txt":          {".": 15, "src/deep/x": 1},
+			"go":           {".": 0, "src": 20, "src/deep/x": 7},
+			"no extension": {".": 7},
+		},
+	)
+	if stats.Skipped != 0 || len(stats.Errors) != 0 {
+		t.Errorf("Skipped = %d, Errors = %v, want none", stats.Skipped, stats.Errors)
+	}
+}
+
+func TestScanCaseSensitive(t *testing.T) {
+	dir := writeTree(t, map[string]int{
+		"a.jpg":     3,
+		"b.JPG":     5,
+		"sub/c.Jpg": 7,
+	})
+
+	t.Run("folded", func(t *testing.T) {
+		stats := scanTree(t, dir, scan.Options{})
+		checkStats(t, dir, stats,
+			map[string]int64{"jpg": 15},
+			map[string][]fileSize{"jpg": {{"a.jpg", 3}, {"b.JPG", 5}, {"sub/c.Jpg", 7}}},
+			map[string]map[string]int64{"jpg": {".": 8, "sub": 7}},
+		)
+	})
+
+	t.Run("case sensitive", func(t *testing.T) {
+		stats := scanTree(t, dir, scan.Options{Filter: scan.Filter{CaseSensitive: true}})
+		checkStats(t, dir, stats,
+			map[string]int64{"jpg": 3, "JPG": 5, "Jpg": 7},
+			map[string][]fileSize{
+				"jpg": {{"a.jpg", 3}},
+				"JPG": {{"b.JPG", 5}},
+				"Jpg": {{"sub/c.Jpg", 7}},
+			},
+			map[string]map[string]int64{"jpg": {".": 3}, "JPG": {".": 5}, "Jpg": {"sub": 7}},
+		)
+	})
+
+	t.Run("extension filter", func(t *testing.T) {
+		// -e jpg matches every case unless CaseSensitive
+		stats := scanTree(t, dir, scan.Options{Filter: scan.Filter{Extensions: "jpg"}})
+		if stats.Sizes["jpg"] != 15 {
+			t.Errorf("Sizes = %v, want jpg: 15", stats.Sizes)
+		}
+		stats = scanTree(t, dir, scan.Options{Filter: scan.Filter{Extensions: "jpg", CaseSensitive: true}})
+		if !reflect.DeepEqual(stats.Sizes, map[string]int64{"jpg": 3}) {
+			t.Errorf("Sizes = %v, want jpg: 3 only", stats.Sizes)
+		}
+	})
+}
+
+func TestScanExcludes(t *testing.T) {
+	dir := writeTree(t, map[string]int{
+		"keep.go":                   1,
+		"debug.log":                 2,
+		"node_modules/lib/index.js": 4,
+		"src/app.js":                8,
+		"src/gen/out.js":            16,
+		"src/gen/keep/more.js":      32,
+	})
+
+	tests := []struct {
+		name     string
+		excludes []string
+		sizes    map[string]int64
+		folders  map[string]map[string]int64
+	}{
+		{
+			name:     "component anywhere",
+			excludes: []string{"node_modules", "*.log"},
+			sizes:    map[string]int64{"go": 1, "js": 56},
+			folders: map[string]map[string]int64{
+				"go": {".": 1},
+				"js": {"src": 8, "src/gen": 16, "src/gen/keep": 32},
+			},
+		},
+		{
+			name:     "path from the root",
+			excludes: []string{"src/gen"},
+			sizes:    map[string]int64{"go": 1, "log": 2, "js": 12},
+			folders: map[string]map[string]int64{
+				"go":  {".": 1},
+				"log": {".": 2},
+				"js":  {"node_modules/lib": 4, "src": 8},
+			},
+		},
+		{
+			name:     "double star",
+			excludes: []string{"**/*.js"},
+			sizes:    map[string]int64{"go": 1, "log": 2},
+			folders: map[string]map[string]int64{
+				"go":  {".": 1},
+				"log": {".": 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := scanTree(t, dir, scan.Options{Filter: scan.Filter{Excludes: tt.excludes}})
+			if !reflect.DeepEqual(stats.Sizes, tt.sizes) {
+				t.Errorf("Sizes = %v, want %v", stats.Sizes, tt.sizes)
+			}
+			if got := relFolders(t, dir, stats); !reflect.DeepEqual(got, tt.folders) {
+				t.Errorf("Folders = %v, want %v", got, tt.folders)
+			}
+		})
+	}
+}
+
+func TestScanMaxDepth(t *testing.T) {
+	dir := writeTree(t, map[string]int{
+		"top.md":        1,
+		"a/mid.md":      2,
+		"a/b/bottom.md": 4,
+	})
+	stats := scanTree(t, dir, scan.Options{Filter: scan.Filter{MaxDepth: 2}})
+	checkStats(t, dir, stats,
+		map[string]int64{"md": 3},
+		map[string][]fileSize{"md": {{"a/mid.md", 2}, {"top.md", 1}}},
+		map[string]map[string]int64{"md": {".": 1, "a": 2}},
+	)
+}
+
+func TestScanMissingRoot(t *testing.T) {
+	if _, err := scan.Scan(filepath.Join(t.TempDir(), "missing"), scan.Options{}); err == nil {
+		t.Error("Scan of a missing root succeeded, want an error")
+	}
+}
@@ -0,0 +1,181 @@
+package scan
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// spillRecord is one file as written to a spill run
+type spillRecord struct {
+	Ext  string
+	File FileDetail
+}
+
+// spillStore keeps per-file records on disk instead of in Stats.Files.
+// Records are buffered up to budget, sorted, and written out as sorted runs;
+// reading them back is a k-way merge, so at most budget records (plus one per
+// run) are held in memory at any time.
+type spillStore struct {
+	dir    string
+	budget int
+	less   func(a, b FileDetail) bool
+	buf    []spillRecord
+	runs   []string
+	err    error
+}
+
+func newSpillStore(budget int, less func(a, b FileDetail) bool) (*spillStore, error) {
+	if budget <= 0 {
+		return nil, fmt.Errorf("spill budget must be positive")
+	}
+	dir, err := os.MkdirTemp("", "extdust-spill-")
+	if err != nil {
+		return nil, fmt.Errorf("error creating spill directory: %w", err)
+	}
+	return &spillStore{dir: dir, budget: budget, less: less}, nil
+}
+
+func (s *spillStore) add(ext string, f FileDetail) {
+	s.buf = append(s.buf, spillRecord{Ext: ext, File: f})
+	if len(s.buf) >= s.budget {
+		s.flush()
+	}
+}
+
+// flush sorts the in-memory buffer and writes it out as a new run
+func (s *spillStore) flush() {
+	if len(s.buf) == 0 || s.err != nil {
+		return
+	}
+	sort.Slice(s.buf, func(i, j int) bool {
+		return s.less(s.buf[i].File, s.buf[j].File)
+	})
+
+	name := filepath.Join(s.dir, fmt.Sprintf("run-%d", len(s.runs)))
+	f, err := os.Create(name)
+	if err != nil {
+		s.err = fmt.Errorf("error creating spill run: %w", err)
+		return
+	}
+	defer f.Close()
+
+	enc := gob.NewEncoder(f)
+	for i := range s.buf {
+		if err := enc.Encode(&s.buf[i]); err != nil {
+			s.err = fmt.Errorf("error writing spill run: %w", err)
+			return
+		}
+	}
+	s.runs = append(s.runs, name)
+	s.buf = s.buf[:0]
+}
+
+// runReader yields the records of one sorted run in order
+type runReader struct {
+	f   *os.File
+	dec *gob.Decoder
+	cur spillRecord
+}
+
+func (r *runReader) next() (bool, error) {
+	// gob leaves zero fields out of the stream, so decoding over the previous
+	// record would keep e.g. its Size for an empty file
+	r.cur = spillRecord{}
+	if err := r.dec.Decode(&r.cur); err != nil {
+		if err == io.EOF {
+			return false, nil
+		}
+		return false, fmt.Errorf("error reading spill run: %w", err)
+	}
+	return true, nil
+}
+
+// runHeap orders run readers by their current record
+type runHeap struct {
+	readers []*runReader
+	less    func(a, b FileDetail) bool
+}
+
+func (h *runHeap) Len() int           { return len(h.readers) }
+func (h *runHeap) Less(i, j int) bool { return h.less(h.readers[i].cur.File, h.readers[j].cur.File) }
+func (h *runHeap) Swap(i, j int)      { h.readers[i], h.readers[j] = h.readers[j], h.readers[i] }
+func (h *runHeap) Push(x any)         { h.readers = append(h.readers, x.(*runReader)) }
+func (h *runHeap) Pop() any {
+	old := h.readers
+	r := old[len(old)-1]
+	h.readers = old[:len(old)-1]
+	return r
+}
+
+// merge streams every spilled record in sorted order. Returning false from fn stops early.
+func (s *spillStore) merge(fn func(spillRecord) bool) error {
+	s.flush()
+	if s.err != nil {
+		return s.err
+	}
+
+	h := &runHeap{less: s.less}
+	defer func() {
+		for _, r := range h.readers {
+			r.f.Close()
+		}
+	}()
+	for _, name := range s.runs {
+		f, err := os.Open(name)
+		if err != nil {
+			return fmt.Errorf("error opening spill run: %w", err)
+		}
+		r := &runReader{f: f, dec: gob.NewDecoder(f)}
+		ok, err := r.next()
+		if err != nil {
+			f.Close()
+			return err
+		}
+		if !ok {
+			f.Close()
+			continue
+		}
+		h.readers = append(h.readers, r)
+	}
+	heap.Init(h)
+
+	for h.Len() > 0 {
+		r := h.readers[0]
+		if !fn(r.cur) {
+			return nil
+		}
+		ok, err := r.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			r.f.Close()
+			heap.Pop(h)
+		}
+	}
+	return nil
+}
+
+// topN returns, per extension, the first limit records in sort order
+func (s *spillStore) topN(limit int) (map[string][]FileDetail, error) {
+	files := make(map[string][]FileDetail)
+	err := s.merge(func(rec spillRecord) bool {
+		if len(files[rec.Ext]) < limit {
+			files[rec.Ext] = append(files[rec.Ext], rec.File)
+		}
+		return true
+	})
+	return files, err
+}
+
+// cleanup removes the spill directory and all runs
+func (s *spillStore) cleanup() {
+	os.RemoveAll(s.dir)
+}
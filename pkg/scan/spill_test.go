@@ -0,0 +1,44 @@
+package scan_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+func TestSpillKeepsZeroSizes(t *testing.T) {
+	files := map[string]int{
+		"a.txt":     5000,
+		"b.txt":     0,
+		"c.txt":     0,
+		"d.txt":     7,
+		"sub/e.txt": 0,
+		"sub/f.go":  3,
+		"sub/g.go":  0,
+	}
+	dir := writeTree(t, files)
+	// a budget of 2 writes several runs, so records are merged back from disk
+	stats := scanTree(t, dir, scan.Options{Spill: true, SpillBudget: 2})
+
+	got := make(map[string]int)
+	if err := stats.EachFile(func(_ string, f scan.FileDetail) {
+		got[relPath(t, dir, f.Path)] = int(f.Size)
+	}); err != nil {
+		t.Fatalf("EachFile: %v", err)
+	}
+	if !reflect.DeepEqual(got, files) {
+		t.Errorf("EachFile sizes = %v, want %v", got, files)
+	}
+
+	if err := stats.Unspill(10); err != nil {
+		t.Fatalf("Unspill: %v", err)
+	}
+	want := map[string][]fileSize{
+		"txt": {{"a.txt", 5000}, {"b.txt", 0}, {"c.txt", 0}, {"d.txt", 7}, {"sub/e.txt", 0}},
+		"go":  {{"sub/f.go", 3}, {"sub/g.go", 0}},
+	}
+	if got := relFiles(t, dir, stats); !reflect.DeepEqual(got, want) {
+		t.Errorf("Files after Unspill = %v, want %v", got, want)
+	}
+}
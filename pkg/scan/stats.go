@@ -0,0 +1,209 @@
+package scan
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+type FileDetail struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Stats is the result of a scan, keyed by extension (or whatever Options.Classify returns)
+type Stats struct {
+	Sizes   map[string]int64
+	Files   map[string][]FileDetail
+	Folders map[string]map[string]int64
+	Counts  map[string]int // number of files per extension
+	Skipped int            // files that could not be statted
+	Errors  []ScanError    // why each skipped file or directory could not be read
+
+	spill     *spillStore      // when set, file details go to disk instead of Files
+	progress  func(size int64) // when set, called for every recorded file
+	onError   func(ScanError)  // when set, called for every recorded error
+	links     map[fileID]bool  // when set, hardlinked files already recorded
+	diskUsage bool             // record allocated instead of apparent sizes
+}
+
+func NewStats() *Stats {
+	return &Stats{
+		Sizes:   make(map[string]int64),
+		Files:   make(map[string][]FileDetail),
+		Folders: make(map[string]map[string]int64),
+		Counts:  make(map[string]int),
+	}
+}
+
+// ScanError is a file or directory that could not be read
+type ScanError struct {
+	Path string `json:"path"`
+	Err  string `json:"error"`
+}
+
+// AddError counts path as skipped and keeps err for the error summary.
+// The path is dropped from err's message, since it is stored separately.
+func (s *Stats) AddError(path string, err error) {
+	s.Skipped++
+	msg := err.Error()
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		msg = pathErr.Op + ": " + pathErr.Err.Error()
+	}
+	s.Errors = append(s.Errors, ScanError{Path: path, Err: msg})
+	if s.onError != nil {
+		s.onError(s.Errors[len(s.Errors)-1])
+	}
+}
+
+// firstLink reports whether info should be recorded: always, unless
+// Options.HardlinksOnce is set and another link to the same file was seen
+func (s *Stats) firstLink(info fs.FileInfo) bool {
+	if s.links == nil {
+		return true
+	}
+	id, ok := hardlinkID(info)
+	if !ok {
+		return true
+	}
+	if s.links[id] {
+		return false
+	}
+	s.links[id] = true
+	return true
+}
+
+// sizeOf is the size recorded for info: its length, or with Options.DiskUsage
+// the space allocated for it where the platform reports that
+func (s *Stats) sizeOf(info fs.FileInfo) int64 {
+	if s.diskUsage {
+		if n, ok := allocatedSize(info); ok {
+			return n
+		}
+	}
+	return info.Size()
+}
+
+// add records a single file under key fileExt in the per-extension, per-file and per-folder maps
+func (s *Stats) add(fileExt, filePath string, fileSize int64, modTime time.Time) {
+	s.Sizes[fileExt] += fileSize
+	s.Counts[fileExt]++
+	if s.progress != nil {
+		s.progress(fileSize)
+	}
+	detail := FileDetail{Path: filePath, Size: fileSize, ModTime: modTime}
+	if s.spill != nil {
+		s.spill.add(fileExt, detail)
+	} else {
+		s.Files[fileExt] = append(s.Files[fileExt], detail)
+	}
+
+	dir := filepath.Dir(filePath)
+	if _, exists := s.Folders[fileExt]; !exists {
+		s.Folders[fileExt] = make(map[string]int64)
+	}
+	s.Folders[fileExt][dir] += fileSize
+}
+
+// EachFile calls fn for every recorded file, reading spilled records back from disk if needed
+func (s *Stats) EachFile(fn func(ext string, f FileDetail)) error {
+	if s.spill != nil {
+		return s.spill.merge(func(rec spillRecord) bool {
+			fn(rec.Ext, rec.File)
+			return true
+		})
+	}
+	for ext, files := range s.Files {
+		for _, f := range files {
+			fn(ext, f)
+		}
+	}
+	return nil
+}
+
+// Unspill loads the first limit files per extension back from disk into
+// Files and removes the spill directory. It is a no-op unless Options.Spill was set.
+func (s *Stats) Unspill(limit int) error {
+	if s.spill == nil {
+		return nil
+	}
+	defer s.spill.cleanup()
+
+	files, err := s.spill.topN(limit)
+	s.Files = files
+	s.spill = nil
+	return err
+}
+
+// FileLess returns the ordering used for the per-extension file listing.
+// detailSort is "size", "mtime" or "name" (by path, A to Z; reverseSize
+// does not apply). Ties fall back to the path, so output does
+// not depend on scan order.
+func FileLess(detailSort string, reverseSize bool) func(a, b FileDetail) bool {
+	var less func(a, b FileDetail) bool
+	switch {
+	case detailSort == "mtime" && reverseSize:
+		// by modification time: reversed = oldest first
+		less = func(a, b FileDetail) bool { return a.ModTime.Before(b.ModTime) }
+	case detailSort == "mtime":
+		// newest first
+		less = func(a, b FileDetail) bool { return a.ModTime.After(b.ModTime) }
+	case detailSort == "name":
+		// the path tie-break below does all the work
+		less = func(a, b FileDetail) bool { return false }
+	case reverseSize:
+		// by size, in the same direction as the summary: reversed = smallest first
+		less = func(a, b FileDetail) bool { return a.Size < b.Size }
+	default:
+		// default = largest first
+		less = func(a, b FileDetail) bool { return a.Size > b.Size }
+	}
+	return func(a, b FileDetail) bool {
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.Path < b.Path
+	}
+}
+
+// SortedExtensions returns the list of known extensions, sorted by size (or by
+// name or file count). Ties in size or count fall back to the extension name so
+// output is stable.
+func SortedExtensions(sizes map[string]int64, counts map[string]int, sortName, sortCount, reverseSize bool) []string {
+	var exts []string
+	for ext := range sizes {
+		exts = append(exts, ext)
+	}
+
+	if sortName {
+		sort.Strings(exts)
+		return exts
+	}
+
+	key := func(ext string) int64 { return sizes[ext] }
+	if sortCount {
+		key = func(ext string) int64 { return int64(counts[ext]) }
+	}
+
+	sort.Slice(exts, func(i, j int) bool {
+		ki, kj := key(exts[i]), key(exts[j])
+		if ki == kj {
+			return exts[i] < exts[j]
+		}
+		if reverseSize {
+			// smallest first
+			return ki < kj
+		}
+		// default = largest first
+		return ki > kj
+	})
+
+	return exts
+}
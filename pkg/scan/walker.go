@@ -0,0 +1,203 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// scanNative walks root with filepath.WalkDir and fills Stats the same
+// way scanFiles does with fd's output, keying each file by classify. Like the
+// fd invocation it includes hidden files unless Filter.NoHidden, and only
+// honours .gitignore files with Filter.Gitignore (and .ignore and .fdignore
+// files with Filter.RespectIgnore), .gitignore ones only inside a git
+// repository with Filter.GitignoreInReposOnly. Symlinks are skipped unless Filter.FollowSymlinks is
+// set; then every directory is remembered by device and inode, so a link back
+// to an ancestor (or to a directory already walked) is not descended again.
+// Unreadable directories and files, and broken symlinks, are recorded with
+// Stats.AddError and the walk continues. With jobs > 1, up to jobs files are
+// classified at once (see classifyPool). Cancelling ctx stops the walk with ctx.Err().
+func scanNative(ctx context.Context, root string, filter Filter, stats *Stats, classify func(string) string, jobs int) error {
+	if _, err := os.Stat(root); err != nil {
+		return fmt.Errorf("error reading search path: %w", err)
+	}
+
+	// record adds a counted file, or with err an unreadable path, to stats
+	record := func(path string, info fs.FileInfo, err error) {
+		if err != nil {
+			stats.AddError(path, err)
+			return
+		}
+		stats.add(classify(path), path, stats.sizeOf(info), info.ModTime())
+	}
+	if jobs > 1 {
+		var finish func()
+		record, finish = classifyPool(stats, classify, jobs)
+		defer finish()
+	}
+
+	var ignores *ignoreMatcher
+	if filter.RespectIgnore {
+		ignores = newIgnoreMatcher(fdIgnoreFiles)
+	} else if filter.Gitignore {
+		ignores = newIgnoreMatcher(gitignoreFiles)
+	}
+	if ignores != nil && filter.GitignoreInReposOnly {
+		_, inRepo := FindRepoRoot(root)
+		ignores.requireRepo(inRepo)
+	}
+
+	var seen map[fileID]bool
+	if filter.FollowSymlinks {
+		seen = make(map[fileID]bool)
+	}
+
+	// countFile records a regular file that passed the directory checks
+	countFile := func(path, rel string, info fs.FileInfo) {
+		if !filter.matchFile(rel, false) {
+			return
+		}
+		if ignores != nil && ignores.ignored(rel, false) {
+			return
+		}
+		if !filter.matchInfo(info) || !stats.firstLink(info) {
+			return
+		}
+		record(path, info, nil)
+	}
+
+	var visit fs.WalkDirFunc
+	visit = func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			record(path, nil, err)
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		rel = filepath.ToSlash(rel)
+
+		if seen != nil && d.Type()&fs.ModeSymlink != 0 {
+			info, err := os.Stat(path)
+			if err != nil {
+				record(path, nil, err)
+				return nil
+			}
+			if info.Mode().IsRegular() {
+				countFile(path, rel, info)
+				return nil
+			}
+			if !info.IsDir() {
+				return nil
+			}
+			if id, ok := fileIDOf(info); !ok || seen[id] {
+				// already walked (a loop, or a second link to it), or no
+				// way to tell on this platform: don't descend
+				return nil
+			}
+			// walking "link/" makes WalkDir resolve the link itself, while
+			// the paths it reports stay under the link
+			err = filepath.WalkDir(path+string(filepath.Separator), visit)
+			if err == fs.SkipDir {
+				return nil
+			}
+			return err
+		}
+
+		if d.IsDir() {
+			// prune excluded directories instead of filtering what is below them
+			if filter.skipDir(rel) || (ignores != nil && rel != "." && ignores.ignored(rel, true)) {
+				return fs.SkipDir
+			}
+			if seen != nil {
+				info, err := d.Info()
+				if err != nil {
+					record(path, nil, err)
+					return fs.SkipDir
+				}
+				if id, ok := fileIDOf(info); ok {
+					if seen[id] {
+						return fs.SkipDir
+					}
+					seen[id] = true
+				}
+			}
+			if ignores != nil {
+				ignores.load(path, rel)
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			record(path, nil, err)
+			return nil
+		}
+		countFile(path, rel, info)
+		return nil
+	}
+	return filepath.WalkDir(root, visit)
+}
+
+// classifyPool returns a record function for scanNative that classifies files
+// on jobs goroutines, since Options.Classify may read each file (as
+// ClassifyMIME does), while a single goroutine adds them and the errors to
+// stats in the order they are done. record must be called from one goroutine;
+// finish waits until everything passed to it is in stats.
+func classifyPool(stats *Stats, classify func(string) string, jobs int) (record func(path string, info fs.FileInfo, err error), finish func()) {
+	type entry struct {
+		path string
+		info fs.FileInfo
+		err  error
+		key  string
+	}
+	pending := make(chan entry, jobs*4)
+	results := make(chan entry, jobs*4)
+
+	var workers sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for e := range pending {
+				if e.err == nil {
+					e.key = classify(e.path)
+				}
+				results <- e
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range results {
+			if e.err != nil {
+				stats.AddError(e.path, e.err)
+				continue
+			}
+			stats.add(e.key, e.path, stats.sizeOf(e.info), e.info.ModTime())
+		}
+	}()
+
+	record = func(path string, info fs.FileInfo, err error) {
+		pending <- entry{path: path, info: info, err: err}
+	}
+	finish = func() {
+		close(pending)
+		workers.Wait()
+		close(results)
+		<-done
+	}
+	return record, finish
+}
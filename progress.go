@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// progressReporter prints a single, self-overwriting status line with the
+// number of files and bytes seen so far. It is driven from scan.Options.Progress,
+// which always runs on one goroutine, so it needs no locking.
+type progressReporter struct {
+	out      io.Writer
+	interval time.Duration
+	last     time.Time
+	files    int
+	bytes    int64
+	printed  bool
+}
+
+func newProgressReporter(out io.Writer) *progressReporter {
+	return &progressReporter{out: out, interval: 100 * time.Millisecond, last: time.Now()}
+}
+
+func (p *progressReporter) update(size int64) {
+	p.files++
+	p.bytes += size
+	// only look at the clock every so often to keep the hot path cheap
+	if p.files%256 != 0 {
+		return
+	}
+	if now := time.Now(); now.Sub(p.last) >= p.interval {
+		p.last = now
+		p.printed = true
+		fmt.Fprintf(p.out, "\r\033[KScanning... %s files, %s", formatCount(int64(p.files)), formatSize(p.bytes))
+	}
+}
+
+// finish clears the status line so the report starts on a clean line
+func (p *progressReporter) finish() {
+	if p.printed {
+		fmt.Fprint(p.out, "\r\033[K")
+	}
+}
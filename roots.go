@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// expandGlobRoots expands a --glob path pattern into the directories (and
+// archives) it matches. Matches of a single pattern all sit at the same depth,
+// so they can never contain one another and nothing is counted twice.
+func expandGlobRoots(pattern string) ([]string, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+
+	var roots []string
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() || scan.IsArchiveRoot(m) {
+			roots = append(roots, m)
+		}
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no directories match %q", pattern)
+	}
+	return roots, nil
+}
+
+// dedupeRoots drops roots that are the same as, or inside, an earlier or
+// later root, so files reachable from overlapping roots are counted once.
+// Roots are compared by absolute path; the first spelling given is kept.
+func dedupeRoots(roots []string) []string {
+	abs := make([]string, len(roots))
+	for i, r := range roots {
+		a, err := filepath.Abs(r)
+		if err != nil {
+			a = filepath.Clean(r)
+		}
+		abs[i] = a
+	}
+
+	var kept []string
+	for i, r := range roots {
+		covered := false
+		for j := range roots {
+			if i == j {
+				continue
+			}
+			// inside another root, or a repeat of an earlier one
+			if isUnder(abs[i], abs[j]) || (abs[i] == abs[j] && j < i) {
+				covered = true
+				fmt.Fprintf(os.Stderr, "Skipping %s: already covered by %s\n", r, roots[j])
+				break
+			}
+		}
+		if !covered {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// isUnder reports whether p lies strictly inside dir
+func isUnder(p, dir string) bool {
+	if p == dir {
+		return false
+	}
+	if dir == string(filepath.Separator) {
+		return true
+	}
+	return strings.HasPrefix(p, dir+string(filepath.Separator))
+}
+
+// commonRoot returns the deepest directory containing every root
+func commonRoot(roots []string) string {
+	if len(roots) == 1 {
+		return roots[0]
+	}
+	common := filepath.Dir(filepath.Clean(roots[0]))
+	for _, r := range roots[1:] {
+		r = filepath.Clean(r)
+		for common != "." && common != string(filepath.Separator) &&
+			r != common && !strings.HasPrefix(r, common+string(filepath.Separator)) {
+			common = filepath.Dir(common)
+		}
+	}
+	return common
+}
+
+// redactRootFor is the prefix shortened to "." in listed paths: the common
+// root of roots, or nothing with absolute
+func redactRootFor(roots []string, absolute bool) string {
+	if absolute {
+		return ""
+	}
+	return commonRoot(roots)
+}
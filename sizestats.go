@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// sizeStats describes the distribution of file sizes within one extension
+type sizeStats struct {
+	Min    int64 `json:"min"`
+	Max    int64 `json:"max"`
+	Mean   int64 `json:"mean"`
+	Median int64 `json:"median"`
+}
+
+// collectSizes returns every file size per extension. It reads all files, so
+// it must run before the stats are unspilled.
+func collectSizes(stats *scan.Stats) (map[string][]int64, error) {
+	sizes := make(map[string][]int64)
+	err := stats.EachFile(func(ext string, f scan.FileDetail) {
+		sizes[ext] = append(sizes[ext], f.Size)
+	})
+	return sizes, err
+}
+
+// foldSizes moves the sizes of extensions that collapseToTop merged into the
+// label bucket there as well
+func foldSizes(sizes map[string][]int64, stats *scan.Stats, label string) {
+	for ext, list := range sizes {
+		if _, kept := stats.Sizes[ext]; !kept {
+			sizes[label] = append(sizes[label], list...)
+			delete(sizes, ext)
+		}
+	}
+}
+
+// summarizeSizes computes min, max, mean and median of each extension's sizes.
+// The median of an even number of files is the mean of the middle two.
+func summarizeSizes(sizes map[string][]int64) map[string]sizeStats {
+	summary := make(map[string]sizeStats, len(sizes))
+	for ext, list := range sizes {
+		if len(list) == 0 {
+			continue
+		}
+		sorted := append([]int64(nil), list...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		var total int64
+		for _, n := range sorted {
+			total += n
+		}
+		mid := len(sorted) / 2
+		median := sorted[mid]
+		if len(sorted)%2 == 0 {
+			median = (sorted[mid-1] + sorted[mid]) / 2
+		}
+		summary[ext] = sizeStats{
+			Min:    sorted[0],
+			Max:    sorted[len(sorted)-1],
+			Mean:   total / int64(len(sorted)),
+			Median: median,
+		}
+	}
+	return summary
+}
+
+// formatSizeStats renders s as the line shown under an extension header
+func formatSizeStats(s sizeStats) string {
+	return fmt.Sprintf("min %s, median %s, mean %s, max %s",
+		formatSize(s.Min), formatSize(s.Median), formatSize(s.Mean), formatSize(s.Max))
+}
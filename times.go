@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// timeRange is the newest and oldest modification time within one extension
+type timeRange struct {
+	Newest time.Time `json:"newest"`
+	Oldest time.Time `json:"oldest"`
+}
+
+// collectTimes returns the modification time range of each extension. It
+// reads all files, so it must run before the stats are unspilled.
+func collectTimes(stats *scan.Stats) (map[string]timeRange, error) {
+	ranges := make(map[string]timeRange)
+	err := stats.EachFile(func(ext string, f scan.FileDetail) {
+		r, ok := ranges[ext]
+		if !ok || f.ModTime.After(r.Newest) {
+			r.Newest = f.ModTime
+		}
+		if !ok || f.ModTime.Before(r.Oldest) {
+			r.Oldest = f.ModTime
+		}
+		ranges[ext] = r
+	})
+	return ranges, err
+}
+
+// foldTimes merges the ranges of extensions that collapseToTop folded into
+// the label bucket there as well
+func foldTimes(ranges map[string]timeRange, stats *scan.Stats, label string) {
+	for ext, r := range ranges {
+		if _, kept := stats.Sizes[ext]; kept {
+			continue
+		}
+		folded, ok := ranges[label]
+		if !ok || r.Newest.After(folded.Newest) {
+			folded.Newest = r.Newest
+		}
+		if !ok || r.Oldest.Before(folded.Oldest) {
+			folded.Oldest = r.Oldest
+		}
+		ranges[label] = folded
+		delete(ranges, ext)
+	}
+}
+
+// formatAgo describes t relative to now in the largest whole unit, e.g.
+// "3 days ago" or "2 years ago"; months are 30 days and years 365 days
+func formatAgo(t, now time.Time) string {
+	d := now.Sub(t)
+	suffix := "ago"
+	if d < 0 {
+		d, suffix = -d, "from now"
+	}
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"week", 7 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, u := range units {
+		if n := int(d / u.size); n >= 1 {
+			if n == 1 {
+				return fmt.Sprintf("1 %s %s", u.name, suffix)
+			}
+			return fmt.Sprintf("%d %ss %s", n, u.name, suffix)
+		}
+	}
+	return "just now"
+}
+
+// formatTimeRange renders a range as "newest: 3 days ago, oldest: 2 years ago"
+func formatTimeRange(r timeRange, now time.Time) string {
+	return fmt.Sprintf("newest: %s, oldest: %s", formatAgo(r.Newest, now), formatAgo(r.Oldest, now))
+}
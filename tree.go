@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// treeNode is a directory with the size of every counted file below it
+type treeNode struct {
+	Path     string      `json:"path"`
+	Size     int64       `json:"size"`
+	Children []*treeNode `json:"children,omitempty"`
+	Hidden   int         `json:"hidden_children,omitempty"` // children cut by --limit
+	HiddenSz int64       `json:"hidden_size,omitempty"`     // their combined size
+}
+
+// buildTree folds the per-extension folder sizes into one directory tree per
+// root. Each directory's size includes its subdirectories. Children are
+// ordered largest first and cut at limit per directory; maxDepth > 0 stops
+// the tree that many levels below the root.
+func buildTree(stats *scan.Stats, roots []string, limit, maxDepth int) []*treeNode {
+	// paths are made absolute so relative roots such as "." line up
+	direct := make(map[string]int64)
+	for _, folders := range stats.Folders {
+		for dir, size := range folders {
+			if abs, err := filepath.Abs(dir); err == nil {
+				direct[abs] += size
+			}
+		}
+	}
+
+	var trees []*treeNode
+	for _, shown := range roots {
+		root, err := filepath.Abs(shown)
+		if err != nil {
+			continue
+		}
+		totals := make(map[string]int64)
+		children := make(map[string]map[string]bool)
+		for dir, size := range direct {
+			if dir != root && !isUnder(dir, root) {
+				continue
+			}
+			// add the size to dir and every parent up to the root
+			for p := dir; ; p = filepath.Dir(p) {
+				totals[p] += size
+				if p == root {
+					break
+				}
+				parent := filepath.Dir(p)
+				if children[parent] == nil {
+					children[parent] = make(map[string]bool)
+				}
+				children[parent][p] = true
+			}
+		}
+		if _, ok := totals[root]; !ok {
+			continue
+		}
+
+		var build func(dir string, depth int) *treeNode
+		build = func(dir string, depth int) *treeNode {
+			node := &treeNode{Path: shown, Size: totals[dir]}
+			if rel, err := filepath.Rel(root, dir); err == nil && rel != "." {
+				node.Path = filepath.Join(shown, rel)
+			}
+			if maxDepth > 0 && depth >= maxDepth {
+				return node
+			}
+			subdirs := make([]string, 0, len(children[dir]))
+			for sub := range children[dir] {
+				subdirs = append(subdirs, sub)
+			}
+			sort.Slice(subdirs, func(i, j int) bool {
+				if totals[subdirs[i]] != totals[subdirs[j]] {
+					return totals[subdirs[i]] > totals[subdirs[j]]
+				}
+				return subdirs[i] < subdirs[j]
+			})
+			for i, sub := range subdirs {
+				if i >= limit {
+					node.Hidden++
+					node.HiddenSz += totals[sub]
+					continue
+				}
+				node.Children = append(node.Children, build(sub, depth+1))
+			}
+			return node
+		}
+		trees = append(trees, build(root, 0))
+	}
+	return trees
+}
+
+// printTree prints each tree with the same connectors as the detail view
+func printTree(trees []*treeNode) {
+	fmt.Println("==================================")
+	fmt.Println(" Directory Tree ")
+	fmt.Println("==================================")
+	for _, t := range trees {
+		fmt.Printf("%s (%s)\n", bold(t.Path), colorSize(t.Size))
+		printTreeChildren(t, "")
+	}
+	fmt.Println("==================================")
+}
+
+func printTreeChildren(node *treeNode, indent string) {
+	for i, child := range node.Children {
+		last := i == len(node.Children)-1 && node.Hidden == 0
+		prefix, next := "├──", "│   "
+		if last {
+			prefix, next = "└──", "    "
+		}
+		fmt.Printf("%s%s %s (%s)\n", dim(indent), dim(prefix), filepath.Base(child.Path), colorSize(child.Size))
+		printTreeChildren(child, indent+next)
+	}
+	if node.Hidden > 0 {
+		fmt.Printf("%s%s %s\n", dim(indent), dim("└──"), dim(fmt.Sprintf("… %d more (%s)", node.Hidden, formatSize(node.HiddenSz))))
+	}
+}
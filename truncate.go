@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+// minPathWidth is the narrowest a truncated path is made, however little room is left
+const minPathWidth = 12
+
+// outputWidth returns the column budget for one line of the detail view:
+// maxWidth when set, otherwise the width of stdout if it is a terminal, or 0
+// (no truncation) when output is piped or redirected
+func outputWidth(maxWidth int) int {
+	if maxWidth > 0 {
+		return maxWidth
+	}
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// truncateMiddle shortens p to at most width display columns by replacing
+// the middle with "…", keeping the leading directories and the file name.
+// Widths are measured in terminal cells, so wide characters count double.
+func truncateMiddle(p string, width int) string {
+	if width <= 0 || runewidth.StringWidth(p) <= width {
+		return p
+	}
+
+	// keep the separator in front of the name so the result still reads as a path
+	name := filepath.Base(p)
+	if i := strings.LastIndex(p, string(filepath.Separator)); i >= 0 {
+		name = p[i:]
+	}
+	nameWidth := runewidth.StringWidth(name)
+	if nameWidth+1 >= width {
+		// not even the name fits: keep its end, which holds the extension
+		return "…" + truncateLeft(name, width-1)
+	}
+
+	head := runewidth.Truncate(p[:len(p)-len(name)], width-nameWidth-1, "")
+	return head + "…" + name
+}
+
+// truncateLeft keeps the last width display columns of s
+func truncateLeft(s string, width int) string {
+	runes := []rune(s)
+	used := 0
+	i := len(runes)
+	for i > 0 {
+		w := runewidth.RuneWidth(runes[i-1])
+		if used+w > width {
+			break
+		}
+		used += w
+		i--
+	}
+	return string(runes[i:])
+}
+
+// fitPath truncates p so that a tree line made of prefix, p and suffix fits in width
+func fitPath(prefix, p, suffix string, width int) string {
+	if width <= 0 {
+		return p
+	}
+	room := width - runewidth.StringWidth(prefix) - runewidth.StringWidth(suffix)
+	return truncateMiddle(p, max(room, minPathWidth))
+}
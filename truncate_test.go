@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mattn/go-runewidth"
+)
+
+func TestTruncateMiddle(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		width int
+		want  string
+	}{
+		{"fits", "/srv/data/notes.txt", 40, "/srv/data/notes.txt"},
+		{"ascii", "/srv/data/archive/2024/notes.txt", 20, "/srv/data…/notes.txt"},
+		{"no limit", "/srv/data/archive/2024/notes.txt", 0, "/srv/data/archive/2024/notes.txt"},
+
+		// CJK characters take two columns each
+		{"cjk", "/data/写真/旅行/東京タワー.jpg", 20, "/dat…/東京タワー.jpg"},
+		{"cjk name only", "/data/写真/旅行/東京タワー.jpg", 16, "…/東京タワー.jpg"},
+		// a wide character that would straddle the limit is dropped, not split
+		{"cjk name cut", "/data/写真/旅行/東京タワー.jpg", 10, "…ワー.jpg"},
+		{"cjk fits by runes but not columns", "/漢字漢字.txt", 12, "…字漢字.txt"},
+
+		// emoji are wide too
+		{"emoji in folders", "/home/me/🎉party/🎂🎂🎂/cake.png", 20, "/home/me/…/cake.png"},
+		{"emoji in name", "/home/me/🎉party/🎂🎂🎂/🎂cake🎂.png", 12, "…cake🎂.png"},
+
+		// a combining accent takes no column of its own
+		{"combining mark fits", "/x/cafe\u0301.txt", 11, "/x/cafe\u0301.txt"},
+		{"precomposed", "/srv/café/résumés/notes.txt", 18, "/srv/ca…/notes.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateMiddle(tt.path, tt.width)
+			if got != tt.want {
+				t.Errorf("truncateMiddle(%q, %d) = %q, want %q", tt.path, tt.width, got, tt.want)
+			}
+			if tt.width > 0 && runewidth.StringWidth(got) > tt.width {
+				t.Errorf("truncateMiddle(%q, %d) = %q is %d columns wide", tt.path, tt.width, got, runewidth.StringWidth(got))
+			}
+		})
+	}
+}
+
+func TestFitPath(t *testing.T) {
+	// the tree prefix and the size suffix are measured in columns as well
+	prefix, suffix := "├── ", " (1.00 KiB)"
+	p := "/data/写真/旅行/東京タワー/とても長いファイル名.jpg"
+	for _, width := range []int{40, 50, 60} {
+		got := fitPath(prefix, p, suffix, width)
+		if w := runewidth.StringWidth(prefix + got + suffix); w > width {
+			t.Errorf("fitPath at width %d: %q makes a line of %d columns", width, got, w)
+		}
+	}
+	// never narrower than minPathWidth, however little room is left
+	if got := fitPath(prefix, p, suffix, 10); runewidth.StringWidth(got) > minPathWidth || runewidth.StringWidth(got) < minPathWidth-1 {
+		t.Errorf("fitPath at width 10 = %q, want about %d columns", got, minPathWidth)
+	}
+}
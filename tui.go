@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awsms/extdust/pkg/scan"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// browserSorts are the orders the interactive browser cycles through with "s"
+var browserSorts = []string{"size-desc", "size-asc", "name"}
+
+// browserUnits are the size units cycled through with "u"
+var browserUnits = []string{"binary", "si", "bytes"}
+
+// browser is the --interactive model: the extension summary, and for the
+// extension opened from it a list of its files or its folders
+type browser struct {
+	stats  *scan.Stats
+	exts   []string // extensions in summary order, as shown by the text report
+	total  int64
+	redact string // root that paths are shown relative to (see displayPath)
+
+	ext     string // opened extension; "" shows the summary
+	folders bool   // show the opened extension's folders instead of its files
+	rows    []browserRow
+
+	cursor, offset int
+	sort, units    int // indexes into browserSorts and browserUnits
+	width, height  int
+}
+
+// browserRow is one line of the current list
+type browserRow struct {
+	label string
+	size  int64
+	count int // files, on summary rows only
+}
+
+// runBrowser shows stats in the interactive browser until the user quits
+func runBrowser(stats *scan.Stats, sortedExtensions []string, totalSize int64, redactRoot string) error {
+	b := &browser{stats: stats, exts: sortedExtensions, total: totalSize, redact: redactRoot}
+	switch {
+	case rawBytes:
+		b.units = 2
+	case siUnits:
+		b.units = 1
+	}
+	b.load()
+	_, err := tea.NewProgram(b, tea.WithAltScreen()).Run()
+	return err
+}
+
+// load fills rows for the current level and sort order
+func (b *browser) load() {
+	b.rows = b.rows[:0]
+	order := browserSorts[b.sort]
+	switch {
+	case b.ext == "":
+		for _, ext := range b.shownExtensions() {
+			b.rows = append(b.rows, browserRow{label: extLabel(ext), size: b.stats.Sizes[ext], count: b.stats.Counts[ext]})
+		}
+	case b.folders:
+		for _, f := range sortedFolders(b.stats.Folders[b.ext], sectionLess(order, "size", false)) {
+			b.rows = append(b.rows, browserRow{label: displayPath(f.Path, b.redact), size: f.Size})
+		}
+	default:
+		for _, f := range sortedFiles(b.stats.Files[b.ext], sectionLess(order, "size", false)) {
+			b.rows = append(b.rows, browserRow{label: displayPath(f.Path, b.redact), size: f.Size})
+		}
+	}
+	b.cursor = min(b.cursor, max(len(b.rows)-1, 0))
+	b.scroll()
+}
+
+// filterShown keeps the extensions of exts that are also in shown, in the
+// order of exts, so resorting never brings back hidden extensions
+func filterShown(exts, shown []string) []string {
+	keep := make(map[string]bool, len(shown))
+	for _, ext := range shown {
+		keep[ext] = true
+	}
+	kept := exts[:0]
+	for _, ext := range exts {
+		if keep[ext] {
+			kept = append(kept, ext)
+		}
+	}
+	return kept
+}
+
+// visible is the number of list rows that fit below the header and above the help line
+func (b *browser) visible() int {
+	return max(b.height-4, 1)
+}
+
+// scroll keeps the cursor on screen
+func (b *browser) scroll() {
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	}
+	if b.cursor >= b.offset+b.visible() {
+		b.offset = b.cursor - b.visible() + 1
+	}
+}
+
+func (b *browser) Init() tea.Cmd {
+	return nil
+}
+
+func (b *browser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		b.width, b.height = msg.Width, msg.Height
+		b.scroll()
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return b, tea.Quit
+		case "up", "k":
+			if b.cursor > 0 {
+				b.cursor--
+			}
+		case "down", "j":
+			if b.cursor < len(b.rows)-1 {
+				b.cursor++
+			}
+		case "pgup":
+			b.cursor = max(b.cursor-b.visible(), 0)
+		case "pgdown":
+			b.cursor = max(min(b.cursor+b.visible(), len(b.rows)-1), 0)
+		case "home", "g":
+			b.cursor = 0
+		case "end", "G":
+			b.cursor = max(len(b.rows)-1, 0)
+		case "enter", "right", "l":
+			if b.ext == "" && len(b.rows) > 0 {
+				b.ext = b.shownExtensions()[b.cursor]
+				b.folders = false
+				b.cursor, b.offset = 0, 0
+				b.load()
+			}
+		case "esc", "left", "h", "backspace":
+			if b.ext != "" {
+				// come back to the row of the extension that was open
+				ext := b.ext
+				b.ext = ""
+				b.load()
+				for i, e := range b.shownExtensions() {
+					if e == ext {
+						b.cursor = i
+					}
+				}
+			}
+		case "tab", "f":
+			if b.ext != "" {
+				b.folders = !b.folders
+				b.cursor, b.offset = 0, 0
+				b.load()
+			}
+		case "s":
+			b.sort = (b.sort + 1) % len(browserSorts)
+			b.load()
+		case "u":
+			b.units = (b.units + 1) % len(browserUnits)
+			siUnits = browserUnits[b.units] == "si"
+			rawBytes = browserUnits[b.units] == "bytes"
+		}
+		b.scroll()
+	}
+	return b, nil
+}
+
+// shownExtensions returns the extension keys of the summary rows, in row
+// order. size-desc keeps the summary order, including --top's "other" row.
+func (b *browser) shownExtensions() []string {
+	order := browserSorts[b.sort]
+	if order == "size-desc" {
+		return b.exts
+	}
+	exts := scan.SortedExtensions(b.stats.Sizes, b.stats.Counts, order == "name", false, order == "size-asc")
+	return filterShown(exts, b.exts)
+}
+
+func (b *browser) View() string {
+	var sb strings.Builder
+	width := max(b.width, 40)
+
+	title := fmt.Sprintf("extdust — %s in %d extensions", formatSize(b.total), len(b.exts))
+	if b.ext != "" {
+		section := "files"
+		if b.folders {
+			section = "folders"
+		}
+		title = fmt.Sprintf("%s: %s in %s (%s)", extLabel(b.ext), formatSize(b.stats.Sizes[b.ext]), formatFileCount(b.stats.Counts[b.ext]), section)
+	}
+	fmt.Fprintf(&sb, "%s\n", bold(title))
+	fmt.Fprintf(&sb, "%s\n\n", dim(fmt.Sprintf("sort: %s, units: %s", browserSorts[b.sort], browserUnits[b.units])))
+
+	if len(b.rows) == 0 {
+		sb.WriteString("Nothing to show.\n")
+	}
+	end := min(b.offset+b.visible(), len(b.rows))
+	for i := b.offset; i < end; i++ {
+		row := b.rows[i]
+		size := formatSize(row.size)
+		if b.ext == "" {
+			size += fmt.Sprintf(" (%s)", formatFileCount(row.count))
+		}
+		marker := "  "
+		if i == b.cursor {
+			marker = "> "
+		}
+		// the label gets what is left after the marker and the size column
+		room := max(width-runewidth.StringWidth(size)-4, 10)
+		label := runewidth.Truncate(row.label, room, "…")
+		pad := strings.Repeat(" ", max(room-runewidth.StringWidth(label), 0))
+		line := marker + label + pad + "  " + size
+		if i == b.cursor {
+			line = bold(line)
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	help := "↑/↓ move · enter open · s sort · u units · q quit"
+	if b.ext != "" {
+		help = "↑/↓ move · esc back · tab files/folders · s sort · u units · q quit"
+	}
+	sb.WriteString(dim(help))
+	return sb.String()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/awsms/extdust/pkg/scan"
+)
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+// runWatch scans every interval until ctx is cancelled, clearing the screen
+// and calling draw with each result. A scan that runs into timeout (when
+// set) is drawn as it is, marked partial. A single failed scan is shown and
+// retried on the next tick. runWatch returns nil once ctx is cancelled.
+func runWatch(ctx context.Context, interval, timeout time.Duration, scanOnce func(ctx context.Context) (*scan.Stats, error), draw func(stats *scan.Stats)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		scanCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			scanCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		started := time.Now()
+		stats, err := scanOnce(scanCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		fmt.Print(clearScreen)
+		fmt.Printf("Every %s, last scan %s (took %s)\n\n", interval, started.Format("15:04:05"), time.Since(started).Round(time.Millisecond))
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			draw(stats)
+			fmt.Printf("Scan timed out after %s; results are partial\n", timeout)
+		case err != nil:
+			fmt.Println(err)
+		default:
+			draw(stats)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}